@@ -0,0 +1,294 @@
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/png"
+	"io"
+	"io/fs"
+	"log/slog"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/mp3"
+	"github.com/gopxl/beep/wav"
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+type AssetKind int
+
+const (
+	FontAsset AssetKind = iota
+	SoundAsset
+	ImageAsset
+	TextAsset
+)
+
+func (kind AssetKind) String() string {
+	switch kind {
+	case FontAsset:
+		return "font"
+	case SoundAsset:
+		return "sound"
+	case ImageAsset:
+		return "image"
+	case TextAsset:
+		return "text"
+	}
+	return fmt.Sprintf("AssetKind(%d)", int(kind))
+}
+
+type assetKey struct {
+	kind   AssetKind
+	path   string
+	params string
+}
+
+type assetEntry struct {
+	value   any
+	refs    int
+	release func() error
+	decode  func(data []byte) (any, func() error, error)
+}
+
+// AssetManager loads assets from a single fs.FS and caches them by path and
+// load parameters. Every load takes a reference which must be given back with
+// Release. Assets nobody references are freed by Collect, which World.Reset
+// calls, and everything is freed by ReleaseAll, which World.Close calls.
+type AssetManager struct {
+	mu      sync.Mutex
+	fsys    fs.FS
+	entries map[assetKey]*assetEntry
+	watcher assetWatcher
+}
+
+// Asset is a reference counted handle to a cached asset.
+type Asset[T any] struct {
+	manager *AssetManager
+	key     assetKey
+	entry   *assetEntry
+}
+
+func NewAssetManager(fsys fs.FS) *AssetManager {
+	return &AssetManager{
+		fsys:    fsys,
+		entries: map[assetKey]*assetEntry{},
+		watcher: assetWatcher{
+			files:     map[string]fileState{},
+			listeners: map[int]func(name string){},
+		},
+	}
+}
+
+func (asset *Asset[T]) Get() T {
+	return asset.entry.value.(T)
+}
+
+func (asset *Asset[T]) Path() string {
+	return asset.key.path
+}
+
+// Release gives the handle's reference back to the manager. The handle must not
+// be used afterward.
+func (asset *Asset[T]) Release() {
+	if asset == nil || asset.manager == nil {
+		return
+	}
+	asset.manager.release(asset.key)
+	asset.manager = nil
+}
+
+func (manager *AssetManager) FS() fs.FS {
+	return manager.fsys
+}
+
+// Open opens a file for streaming without caching it, e.g. for music.
+func (manager *AssetManager) Open(name string) (fs.File, error) {
+	return manager.fsys.Open(name)
+}
+
+func (manager *AssetManager) ReadFile(name string) ([]byte, error) {
+	manager.mu.Lock()
+	manager.track(name)
+	manager.mu.Unlock()
+	return fs.ReadFile(manager.fsys, name)
+}
+
+func (manager *AssetManager) Font(name string, size int) (*Asset[*ttf.Font], error) {
+	return loadAsset(manager, assetKey{kind: FontAsset, path: name, params: fmt.Sprint(size)}, func(data []byte) (*ttf.Font, func() error, error) {
+		return decodeFont(data, size)
+	})
+}
+
+func (manager *AssetManager) Sound(name string) (*Asset[*beep.Buffer], error) {
+	return loadAsset(manager, assetKey{kind: SoundAsset, path: name}, func(data []byte) (*beep.Buffer, func() error, error) {
+		return decodeSound(name, data)
+	})
+}
+
+func (manager *AssetManager) Image(name string) (*Asset[*sdl.Surface], error) {
+	return loadAsset(manager, assetKey{kind: ImageAsset, path: name}, decodeImage)
+}
+
+func (manager *AssetManager) Text(name string) (*Asset[string], error) {
+	return loadAsset(manager, assetKey{kind: TextAsset, path: name}, func(data []byte) (string, func() error, error) {
+		return string(data), nil, nil
+	})
+}
+
+// Collect frees every cached asset that is no longer referenced.
+func (manager *AssetManager) Collect() {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	for key, entry := range manager.entries {
+		if entry.refs > 0 {
+			continue
+		}
+		manager.free(key, entry)
+	}
+}
+
+// ReleaseAll frees every cached asset regardless of outstanding references.
+func (manager *AssetManager) ReleaseAll() {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	for key, entry := range manager.entries {
+		if entry.refs > 0 {
+			slog.Warn("Releasing asset that is still referenced", "kind", key.kind, "path", key.path, "refs", entry.refs)
+		}
+		manager.free(key, entry)
+	}
+}
+
+// Close frees every cached asset and closes the file system if it holds
+// resources of its own.
+func (manager *AssetManager) Close() error {
+	manager.ReleaseAll()
+	if closer, ok := manager.fsys.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (manager *AssetManager) release(key assetKey) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	entry, ok := manager.entries[key]
+	if !ok {
+		return
+	}
+	if entry.refs > 0 {
+		entry.refs--
+	}
+}
+
+func (manager *AssetManager) free(key assetKey, entry *assetEntry) {
+	delete(manager.entries, key)
+	if entry.release == nil {
+		return
+	}
+	if err := entry.release(); err != nil {
+		slog.Error("Failed releasing asset", "kind", key.kind, "path", key.path, "error", err)
+	}
+}
+
+func loadAsset[T any](manager *AssetManager, key assetKey, decode func(data []byte) (T, func() error, error)) (*Asset[T], error) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	if entry, ok := manager.entries[key]; ok {
+		entry.refs++
+		return &Asset[T]{manager: manager, key: key, entry: entry}, nil
+	}
+	manager.track(key.path)
+	data, err := fs.ReadFile(manager.fsys, key.path)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s %q: %w", key.kind, key.path, err)
+	}
+	value, release, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s %q: %w", key.kind, key.path, err)
+	}
+	entry := &assetEntry{value: value, refs: 1, release: release, decode: func(data []byte) (any, func() error, error) {
+		return decode(data)
+	}}
+	manager.entries[key] = entry
+	return &Asset[T]{manager: manager, key: key, entry: entry}, nil
+}
+
+func decodeFont(data []byte, size int) (*ttf.Font, func() error, error) {
+	// SDL_ttf reads from the memory for as long as the font is open, so it
+	// must not be Go memory.
+	memory, free := cBytes(data)
+	rw, err := sdl.RWFromMem(memory)
+	if err != nil {
+		free()
+		return nil, nil, err
+	}
+	font, err := ttf.OpenFontRW(rw, 1, size)
+	if err != nil {
+		free()
+		return nil, nil, err
+	}
+	return font, func() error {
+		font.Close()
+		free()
+		return nil
+	}, nil
+}
+
+func decodeSound(name string, data []byte) (*beep.Buffer, func() error, error) {
+	var streamer beep.StreamSeekCloser
+	var format beep.Format
+	var err error
+	switch strings.ToLower(path.Ext(name)) {
+	case ".wav":
+		streamer, format, err = wav.Decode(bytes.NewReader(data))
+	case ".mp3":
+		streamer, format, err = mp3.Decode(io.NopCloser(bytes.NewReader(data)))
+	default:
+		err = fmt.Errorf("unsupported sound format %q", path.Ext(name))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := streamer.Close(); err != nil {
+			slog.Error("Failed closing streamer", "path", name, "error", err)
+		}
+	}()
+	sound := beep.NewBuffer(format)
+	sound.Append(streamer)
+	return sound, nil, nil
+}
+
+func decodeImage(data []byte) (*sdl.Surface, func() error, error) {
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	bounds := decoded.Bounds()
+	rgba := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), decoded, bounds.Min, draw.Src)
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, int32(bounds.Dx()), int32(bounds.Dy()), 32, sdl.PIXELFORMAT_ABGR8888)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := surface.Lock(); err != nil {
+		surface.Free()
+		return nil, nil, err
+	}
+	pixels := surface.Pixels()
+	for y := 0; y < bounds.Dy(); y++ {
+		copy(pixels[y*int(surface.Pitch):], rgba.Pix[y*rgba.Stride:y*rgba.Stride+bounds.Dx()*4])
+	}
+	surface.Unlock()
+	return surface, func() error {
+		surface.Free()
+		return nil
+	}, nil
+}
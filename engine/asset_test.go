@@ -0,0 +1,66 @@
+package engine
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssetManagerCachesAndCollects(t *testing.T) {
+	fsys := fstest.MapFS{"levels/level_0.txt": {Data: []byte("level 0")}}
+	manager := NewAssetManager(fsys)
+
+	first, err := manager.Text("levels/level_0.txt")
+	assert.NoError(t, err)
+	second, err := manager.Text("levels/level_0.txt")
+	assert.NoError(t, err)
+	assert.Same(t, first.entry, second.entry)
+	assert.Equal(t, 2, first.entry.refs)
+
+	first.Release()
+	manager.Collect()
+	assert.Len(t, manager.entries, 1)
+
+	second.Release()
+	manager.Collect()
+	assert.Empty(t, manager.entries)
+
+	_, err = manager.Text("levels/missing.txt")
+	assert.Error(t, err)
+}
+
+func TestAssetManagerReloadsChangedFiles(t *testing.T) {
+	fsys := fstest.MapFS{"levels/level_0.txt": {Data: []byte("before"), ModTime: time.Unix(1, 0)}}
+	manager := NewAssetManager(fsys)
+	text, err := manager.Text("levels/level_0.txt")
+	assert.NoError(t, err)
+
+	reloaded := make(chan string, 1)
+	stop := manager.OnReload(func(name string) {
+		reloaded <- name
+	})
+	manager.Watch(time.Hour)
+
+	poll := func() {
+		// Pretend the interval passed instead of waiting for it.
+		manager.watcher.lastPoll = time.Time{}
+		manager.Poll()
+	}
+
+	poll()
+	assert.Empty(t, reloaded)
+
+	fsys["levels/level_0.txt"] = &fstest.MapFile{Data: []byte("after"), ModTime: time.Unix(2, 0)}
+	manager.Poll()
+	assert.Empty(t, reloaded, "the interval has not passed")
+	poll()
+	assert.Equal(t, "levels/level_0.txt", <-reloaded)
+	assert.Equal(t, "after", text.Get())
+
+	stop()
+	fsys["levels/level_0.txt"] = &fstest.MapFile{Data: []byte("again"), ModTime: time.Unix(3, 0)}
+	poll()
+	assert.Empty(t, reloaded)
+}
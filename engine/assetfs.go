@@ -0,0 +1,136 @@
+package engine
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+)
+
+// OverlayFS is a read-only file system made of layers. Each name is served by
+// the first layer that has it, so earlier layers override later ones.
+type OverlayFS struct {
+	layers []fs.FS
+}
+
+func NewOverlayFS(layers ...fs.FS) *OverlayFS {
+	overlay := &OverlayFS{}
+	for _, layer := range layers {
+		if layer != nil {
+			overlay.layers = append(overlay.layers, layer)
+		}
+	}
+	return overlay
+}
+
+// LayeredAssets builds the usual asset file system: the on-disk directory dir
+// over embedded over the zip archives, in the order given. An empty dir or a
+// dir that does not exist is skipped.
+func LayeredAssets(dir string, embedded fs.FS, archives ...string) (*OverlayFS, error) {
+	var layers []fs.FS
+	if dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			layers = append(layers, os.DirFS(dir))
+		}
+	}
+	layers = append(layers, embedded)
+	for _, archive := range archives {
+		if archive == "" {
+			continue
+		}
+		reader, err := zip.OpenReader(archive)
+		if err != nil {
+			_ = NewOverlayFS(layers...).Close()
+			return nil, err
+		}
+		layers = append(layers, reader)
+	}
+	return NewOverlayFS(layers...), nil
+}
+
+func (overlay *OverlayFS) Layers() []fs.FS {
+	return overlay.layers
+}
+
+func (overlay *OverlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, layer := range overlay.layers {
+		file, err := layer.Open(name)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
+
+func (overlay *OverlayFS) Stat(name string) (fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrInvalid}
+	}
+	for _, layer := range overlay.layers {
+		info, err := fs.Stat(layer, name)
+		if err == nil {
+			return info, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+// ReadDir merges the directory listings of every layer. Entries from earlier
+// layers hide entries with the same name in later ones.
+func (overlay *OverlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	seen := map[string]bool{}
+	var entries []fs.DirEntry
+	found := false
+	for _, layer := range overlay.layers {
+		layerEntries, err := fs.ReadDir(layer, name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		found = true
+		for _, entry := range layerEntries {
+			if seen[entry.Name()] {
+				continue
+			}
+			seen[entry.Name()] = true
+			entries = append(entries, entry)
+		}
+	}
+	if !found {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return entries, nil
+}
+
+// Close closes every layer that holds resources, such as zip archives.
+func (overlay *OverlayFS) Close() error {
+	var errs []error
+	for _, layer := range overlay.layers {
+		if closer, ok := layer.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
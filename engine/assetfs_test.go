@@ -0,0 +1,39 @@
+package engine
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverlayFS(t *testing.T) {
+	disk := fstest.MapFS{
+		"levels/level_3.txt": {Data: []byte("override")},
+	}
+	embedded := fstest.MapFS{
+		"levels/level_0.txt": {Data: []byte("level 0")},
+		"levels/level_3.txt": {Data: []byte("level 3")},
+	}
+	overlay := NewOverlayFS(disk, embedded)
+
+	data, err := fs.ReadFile(overlay, "levels/level_3.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "override", string(data))
+
+	data, err = fs.ReadFile(overlay, "levels/level_0.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "level 0", string(data))
+
+	_, err = overlay.Open("levels/level_1.txt")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	entries, err := fs.ReadDir(overlay, "levels")
+	assert.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"level_0.txt", "level_3.txt"}, names)
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"io/fs"
+	"log/slog"
+	"time"
+)
+
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func (state fileState) equal(other fileState) bool {
+	return state.exists == other.exists && state.size == other.size && state.modTime.Equal(other.modTime)
+}
+
+type assetWatcher struct {
+	interval     time.Duration
+	lastPoll     time.Time
+	files        map[string]fileState
+	listeners    map[int]func(name string)
+	nextListener int
+}
+
+// Watch makes Poll check every file loaded through the manager for changes at
+// most once per interval. Changed assets are decoded again in place, so
+// existing handles see the new value, and OnReload listeners are notified.
+// An interval of zero stops watching.
+func (manager *AssetManager) Watch(interval time.Duration) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	manager.watcher.interval = interval
+	manager.watcher.lastPoll = time.Now()
+}
+
+// OnReload registers a listener called with the name of every changed file.
+// Listeners run on the thread calling Poll. The returned function removes the
+// listener again.
+func (manager *AssetManager) OnReload(listener func(name string)) func() {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	id := manager.watcher.nextListener
+	manager.watcher.nextListener++
+	manager.watcher.listeners[id] = listener
+	return func() {
+		manager.mu.Lock()
+		defer manager.mu.Unlock()
+		delete(manager.watcher.listeners, id)
+	}
+}
+
+// Poll checks watched files for changes if the watch interval has passed.
+// World calls it once per frame.
+func (manager *AssetManager) Poll() {
+	manager.mu.Lock()
+	watcher := &manager.watcher
+	if watcher.interval <= 0 || time.Since(watcher.lastPoll) < watcher.interval {
+		manager.mu.Unlock()
+		return
+	}
+	watcher.lastPoll = time.Now()
+
+	var changed []string
+	for name, state := range watcher.files {
+		current := manager.stat(name)
+		if current.equal(state) {
+			continue
+		}
+		watcher.files[name] = current
+		changed = append(changed, name)
+	}
+	for _, name := range changed {
+		manager.reload(name)
+	}
+	listeners := make([]func(name string), 0, len(watcher.listeners))
+	for _, listener := range watcher.listeners {
+		listeners = append(listeners, listener)
+	}
+	manager.mu.Unlock()
+
+	for _, name := range changed {
+		slog.Info("Asset changed", "path", name)
+		for _, listener := range listeners {
+			listener(name)
+		}
+	}
+}
+
+func (manager *AssetManager) track(name string) {
+	if _, ok := manager.watcher.files[name]; ok {
+		return
+	}
+	manager.watcher.files[name] = manager.stat(name)
+}
+
+func (manager *AssetManager) stat(name string) fileState {
+	info, err := fs.Stat(manager.fsys, name)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+}
+
+func (manager *AssetManager) reload(name string) {
+	for key, entry := range manager.entries {
+		if key.path != name {
+			continue
+		}
+		data, err := fs.ReadFile(manager.fsys, name)
+		if err != nil {
+			slog.Error("Failed reloading asset", "kind", key.kind, "path", name, "error", err)
+			continue
+		}
+		value, release, err := entry.decode(data)
+		if err != nil {
+			slog.Error("Failed decoding reloaded asset", "kind", key.kind, "path", name, "error", err)
+			continue
+		}
+		if entry.release != nil {
+			if err := entry.release(); err != nil {
+				slog.Error("Failed releasing asset", "kind", key.kind, "path", name, "error", err)
+			}
+		}
+		entry.value = value
+		entry.release = release
+	}
+}
@@ -0,0 +1,572 @@
+package engine
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/mp3"
+	"github.com/gopxl/beep/wav"
+)
+
+const (
+	MusicBus = "Music"
+	SFXBus   = "SFX"
+	UIBus    = "UI"
+)
+
+type AudioBus struct {
+	Name   string
+	Volume float64
+	Muted  bool
+}
+
+// Audio mixes sound effects and music into a single AudioOutput. Every voice
+// plays on a named bus whose volume and mute state apply to it while playing.
+type Audio struct {
+	mu        sync.Mutex
+	output    AudioOutput
+	assets    *AssetManager
+	rate      beep.SampleRate
+	buses     map[string]*AudioBus
+	sounds    map[string]*Asset[*beep.Buffer]
+	banks     map[string]*bankState
+	polyphony map[string]int
+	voices    []*Voice
+	music     *musicPlayer
+	scratch   [][2]float64
+	rand      *rand.Rand
+	recording bool
+	recorded  []PlaySound
+}
+
+// Voice is a sound that is playing or has played.
+type Voice struct {
+	audio    *Audio
+	sound    string
+	bus      string
+	volume   float64
+	streamer beep.Streamer
+	stopped  bool
+}
+
+type Playlist struct {
+	Tracks    []string
+	Bus       string
+	Volume    float64
+	Loop      bool
+	Crossfade time.Duration
+	// Gap is the silence between tracks when there is no crossfade.
+	Gap time.Duration
+}
+
+func NewAudio(assets *AssetManager, output AudioOutput) *Audio {
+	audio := &Audio{
+		output:    output,
+		assets:    assets,
+		rate:      output.SampleRate(),
+		buses:     map[string]*AudioBus{},
+		sounds:    map[string]*Asset[*beep.Buffer]{},
+		banks:     map[string]*bankState{},
+		polyphony: map[string]int{},
+		rand:      rand.New(rand.NewPCG(newSeed(), 0)),
+	}
+	for _, name := range []string{MusicBus, SFXBus, UIBus} {
+		audio.buses[name] = &AudioBus{Name: name, Volume: 1}
+	}
+	output.Play(audio)
+	return audio
+}
+
+func (audio *Audio) SampleRate() beep.SampleRate {
+	return audio.rate
+}
+
+// Bus returns a copy of the named bus, creating it if needed.
+func (audio *Audio) Bus(name string) AudioBus {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	return *audio.bus(name)
+}
+
+func (audio *Audio) SetBusVolume(name string, volume float64) {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	audio.bus(name).Volume = volume
+}
+
+func (audio *Audio) SetBusMuted(name string, muted bool) {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	audio.bus(name).Muted = muted
+}
+
+// SetPolyphony limits how many voices of sound can play at once. Starting
+// another one stops the oldest. Zero removes the limit.
+func (audio *Audio) SetPolyphony(sound string, voices int) {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	audio.polyphony[sound] = voices
+}
+
+func (audio *Audio) Play(sound string, bus string, volume float64) (*Voice, error) {
+	return audio.play(sound, bus, volume, 1, false)
+}
+
+func (audio *Audio) Loop(sound string, bus string, volume float64) (*Voice, error) {
+	return audio.play(sound, bus, volume, 1, true)
+}
+
+// Voices returns how many voices of sound are playing.
+func (audio *Audio) Voices(sound string) int {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	count := 0
+	for _, voice := range audio.voices {
+		if voice.sound == sound && !voice.stopped {
+			count++
+		}
+	}
+	return count
+}
+
+// PlayMusic replaces the current playlist. Tracks are streamed from the
+// assets rather than decoded up front.
+func (audio *Audio) PlayMusic(playlist Playlist) {
+	if playlist.Bus == "" {
+		playlist.Bus = MusicBus
+	}
+	if playlist.Volume == 0 {
+		playlist.Volume = 1
+	}
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	if audio.music != nil {
+		audio.music.close()
+	}
+	audio.music = &musicPlayer{audio: audio, playlist: playlist, index: -1}
+}
+
+func (audio *Audio) StopMusic() {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	if audio.music != nil {
+		audio.music.close()
+		audio.music = nil
+	}
+}
+
+func (audio *Audio) Close() error {
+	audio.mu.Lock()
+	for _, voice := range audio.voices {
+		voice.stopped = true
+	}
+	audio.voices = nil
+	if audio.music != nil {
+		audio.music.close()
+		audio.music = nil
+	}
+	for name, sound := range audio.sounds {
+		sound.Release()
+		delete(audio.sounds, name)
+	}
+	audio.mu.Unlock()
+	return audio.output.Close()
+}
+
+// Stream mixes every playing voice. It never runs out of samples.
+func (audio *Audio) Stream(samples [][2]float64) (int, bool) {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	clear(samples)
+	if len(audio.scratch) < len(samples) {
+		audio.scratch = make([][2]float64, len(samples))
+	}
+	scratch := audio.scratch[:len(samples)]
+
+	playing := audio.voices[:0]
+	for _, voice := range audio.voices {
+		if voice.stopped {
+			continue
+		}
+		n, ok := voice.streamer.Stream(scratch)
+		mix(samples[:n], scratch[:n], audio.gain(voice.bus)*voice.volume)
+		if !ok || n < len(samples) {
+			voice.stopped = true
+			continue
+		}
+		playing = append(playing, voice)
+	}
+	clear(audio.voices[len(playing):])
+	audio.voices = playing
+
+	if audio.music != nil {
+		audio.music.stream(samples)
+	}
+	return len(samples), true
+}
+
+func (audio *Audio) Err() error {
+	return nil
+}
+
+func (audio *Audio) play(sound string, bus string, volume float64, pitch float64, loop bool) (*Voice, error) {
+	buffer, err := audio.load(sound)
+	if err != nil {
+		return nil, err
+	}
+	var streamer beep.Streamer
+	if loop {
+		streamer = beep.Loop(-1, buffer.Streamer(0, buffer.Len()))
+	} else {
+		streamer = buffer.Streamer(0, buffer.Len())
+	}
+	if buffer.Format().SampleRate != audio.rate || pitch != 1 {
+		ratio := float64(buffer.Format().SampleRate) / float64(audio.rate) * pitch
+		streamer = beep.ResampleRatio(4, ratio, streamer)
+	}
+	voice := &Voice{audio: audio, sound: sound, bus: bus, volume: volume, streamer: streamer}
+
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	if limit := audio.polyphony[sound]; limit > 0 {
+		var same []*Voice
+		for _, playing := range audio.voices {
+			if playing.sound == sound && !playing.stopped {
+				same = append(same, playing)
+			}
+		}
+		for i := 0; i <= len(same)-limit; i++ {
+			same[i].stopped = true
+		}
+	}
+	audio.voices = append(audio.voices, voice)
+	return voice, nil
+}
+
+func (audio *Audio) load(sound string) (*beep.Buffer, error) {
+	audio.mu.Lock()
+	asset, ok := audio.sounds[sound]
+	audio.mu.Unlock()
+	if ok {
+		return asset.Get(), nil
+	}
+	asset, err := audio.assets.Sound(sound)
+	if err != nil {
+		return nil, err
+	}
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	if existing, ok := audio.sounds[sound]; ok {
+		asset.Release()
+		return existing.Get(), nil
+	}
+	audio.sounds[sound] = asset
+	return asset.Get(), nil
+}
+
+func (audio *Audio) bus(name string) *AudioBus {
+	bus, ok := audio.buses[name]
+	if !ok {
+		bus = &AudioBus{Name: name, Volume: 1}
+		audio.buses[name] = bus
+	}
+	return bus
+}
+
+func (audio *Audio) gain(name string) float64 {
+	bus := audio.bus(name)
+	if bus.Muted {
+		return 0
+	}
+	return bus.Volume
+}
+
+func (voice *Voice) Stop() {
+	voice.audio.mu.Lock()
+	defer voice.audio.mu.Unlock()
+	voice.stopped = true
+}
+
+func (voice *Voice) Playing() bool {
+	voice.audio.mu.Lock()
+	defer voice.audio.mu.Unlock()
+	return !voice.stopped
+}
+
+func mix(into [][2]float64, samples [][2]float64, gain float64) {
+	for i := range samples {
+		into[i][0] += samples[i][0] * gain
+		into[i][1] += samples[i][1] * gain
+	}
+}
+
+type musicTrack struct {
+	streamer beep.Streamer
+	closer   io.Closer
+	played   int
+	length   int
+}
+
+// musicPlayer streams the tracks of a playlist one after another, fading the
+// next track in over the end of the current one when crossfading.
+type musicPlayer struct {
+	audio    *Audio
+	playlist Playlist
+	index    int
+	current  *musicTrack
+	next     *musicTrack
+	gap      int
+	done     bool
+	scratch  [][2]float64
+}
+
+func (player *musicPlayer) stream(samples [][2]float64) {
+	if len(player.scratch) < len(samples) {
+		player.scratch = make([][2]float64, len(samples))
+	}
+	gain := player.audio.gain(player.playlist.Bus) * player.playlist.Volume
+	fade := player.audio.rate.N(player.playlist.Crossfade)
+	for offset := 0; offset < len(samples) && !player.done; {
+		if player.current == nil {
+			if player.gap > 0 {
+				skip := min(player.gap, len(samples)-offset)
+				player.gap -= skip
+				offset += skip
+				continue
+			}
+			player.current = player.open()
+			if player.current == nil {
+				return
+			}
+		}
+		remaining := player.current.length - player.current.played
+		if fade > 0 && player.next == nil && remaining <= fade && player.hasNext() {
+			player.next = player.open()
+		}
+
+		chunk := len(samples) - offset
+		if fade > 0 && player.next == nil && remaining > fade {
+			// Stop right where the crossfade has to start.
+			chunk = min(chunk, remaining-fade)
+		}
+		n, ok := player.current.streamer.Stream(player.scratch[:chunk])
+		if player.next == nil {
+			mix(samples[offset:offset+n], player.scratch[:n], gain)
+		} else {
+			for i := range n {
+				fadeOut := gain * float64(max(remaining-i, 0)) / float64(fade)
+				samples[offset+i][0] += player.scratch[i][0] * fadeOut
+				samples[offset+i][1] += player.scratch[i][1] * fadeOut
+			}
+		}
+		player.current.played += n
+		if player.next != nil {
+			nextN, _ := player.next.streamer.Stream(player.scratch[:chunk])
+			for i := range nextN {
+				fadeIn := gain - gain*float64(max(remaining-i, 0))/float64(fade)
+				samples[offset+i][0] += player.scratch[i][0] * fadeIn
+				samples[offset+i][1] += player.scratch[i][1] * fadeIn
+			}
+			player.next.played += nextN
+		}
+		offset += max(n, 1)
+		if !ok || n < chunk {
+			player.finishTrack()
+		}
+	}
+}
+
+func (player *musicPlayer) hasNext() bool {
+	return player.playlist.Loop || player.index+1 < len(player.playlist.Tracks)
+}
+
+func (player *musicPlayer) open() *musicTrack {
+	if len(player.playlist.Tracks) == 0 || !player.hasNext() {
+		player.done = true
+		return nil
+	}
+	player.index = (player.index + 1) % len(player.playlist.Tracks)
+	name := player.playlist.Tracks[player.index]
+	track, err := player.audio.openTrack(name)
+	if err != nil {
+		slog.Error("Failed opening music track", "path", name, "error", err)
+		player.done = true
+		return nil
+	}
+	return track
+}
+
+func (player *musicPlayer) finishTrack() {
+	if err := player.current.closer.Close(); err != nil {
+		slog.Error("Failed closing music track", "error", err)
+	}
+	player.current = player.next
+	player.next = nil
+	if player.current == nil {
+		player.gap = player.audio.rate.N(player.playlist.Gap)
+	}
+}
+
+func (player *musicPlayer) close() {
+	for _, track := range []*musicTrack{player.current, player.next} {
+		if track == nil {
+			continue
+		}
+		if err := track.closer.Close(); err != nil {
+			slog.Error("Failed closing music track", "error", err)
+		}
+	}
+	player.current = nil
+	player.next = nil
+	player.done = true
+}
+
+func (audio *Audio) openTrack(name string) (*musicTrack, error) {
+	file, err := audio.assets.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	var decoded beep.StreamSeekCloser
+	var format beep.Format
+	switch strings.ToLower(path.Ext(name)) {
+	case ".mp3":
+		decoded, format, err = mp3.Decode(file)
+	case ".wav":
+		decoded, format, err = wav.Decode(file)
+	default:
+		err = fmt.Errorf("unsupported music format %q", path.Ext(name))
+	}
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	length := decoded.Len()
+	var streamer beep.Streamer = decoded
+	if format.SampleRate != audio.rate {
+		streamer = beep.Resample(4, format.SampleRate, audio.rate, decoded)
+		length = int(float64(length) * float64(audio.rate) / float64(format.SampleRate))
+	}
+	return &musicTrack{streamer: streamer, closer: decoded, length: length}, nil
+}
+
+// AudioSource makes an entity play a sound for as long as it has the
+// component. The sound restarts if the component is replaced with a
+// different sound.
+type AudioSource struct {
+	Sound  string
+	Bus    string
+	Volume float64
+	Loop   bool
+}
+
+// AudioSystem plays the AudioSource components of the world's entities and
+// consumes PlaySound requests. A request on an entity of its own deletes the
+// entity once played; otherwise only the component is removed.
+type AudioSystem struct {
+	group    *Group
+	requests *Group
+	sources  map[uint32]audioSourceVoice
+}
+
+type audioSourceVoice struct {
+	source AudioSource
+	voice  *Voice
+}
+
+func (playing audioSourceVoice) stop() {
+	if playing.voice != nil {
+		playing.voice.Stop()
+	}
+}
+
+func (s *AudioSystem) Initialize(world *World) error {
+	s.sources = map[uint32]audioSourceVoice{}
+	s.group = world.GetGroup(&AllOfComponentMatcher{Components: []reflect.Type{reflect.TypeOf(AudioSource{})}})
+	s.requests = world.GetGroup(&AllOfComponentMatcher{Components: []reflect.Type{reflect.TypeOf(PlaySound{})}})
+	return nil
+}
+
+func (s *AudioSystem) Update(world *World) error {
+	if world.Audio == nil {
+		return nil
+	}
+	s.playRequests(world)
+	seen := make(map[uint32]bool, len(s.sources))
+	for _, entity := range s.group.GetEntities() {
+		seen[entity] = true
+		component, ok := world.GetEntityComponent(entity, reflect.TypeOf(AudioSource{}))
+		if !ok {
+			continue
+		}
+		source := component.(AudioSource)
+		if playing, ok := s.sources[entity]; ok {
+			if playing.source == source {
+				continue
+			}
+			playing.stop()
+		}
+		volume := source.Volume
+		if volume == 0 {
+			volume = 1
+		}
+		bus := source.Bus
+		if bus == "" {
+			bus = SFXBus
+		}
+		var voice *Voice
+		var err error
+		if source.Loop {
+			voice, err = world.Audio.Loop(source.Sound, bus, volume)
+		} else {
+			voice, err = world.Audio.Play(source.Sound, bus, volume)
+		}
+		if err != nil {
+			// Remember the source anyway so it is not retried every frame.
+			world.EntityLogger(entity).Error("Failed playing sound", "sound", source.Sound, "error", err)
+		}
+		s.sources[entity] = audioSourceVoice{source: source, voice: voice}
+	}
+	for entity, playing := range s.sources {
+		if !seen[entity] {
+			playing.stop()
+			delete(s.sources, entity)
+		}
+	}
+	return nil
+}
+
+// Close drops requests that are still pending and stops every AudioSource.
+// The world's Audio itself is closed with the world.
+func (s *AudioSystem) Close() error {
+	for entity, playing := range s.sources {
+		playing.stop()
+		delete(s.sources, entity)
+	}
+	return nil
+}
+
+func (s *AudioSystem) playRequests(world *World) {
+	for _, entity := range s.requests.GetEntities() {
+		component, ok := world.GetEntityComponent(entity, reflect.TypeOf(PlaySound{}))
+		if !ok {
+			continue
+		}
+		request := component.(PlaySound)
+		if _, err := world.Audio.Request(request); err != nil {
+			world.EntityLogger(entity).Error("Failed playing sound", "sound", request.Name, "error", err)
+		}
+		if world.components.componentCount(entity) == 1 {
+			world.DeleteEntity(entity)
+		} else {
+			world.RemoveComponent(entity, reflect.TypeOf(PlaySound{}))
+		}
+	}
+}
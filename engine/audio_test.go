@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRate beep.SampleRate = 8000
+
+// testLevel is the decoded sample value of wavData(n, 1<<14).
+const testLevel = 0.25
+
+// wavData encodes a mono 16-bit PCM wav holding a constant signal.
+func wavData(samples int, amplitude int16) []byte {
+	var buf bytes.Buffer
+	dataSize := uint32(samples * 2)
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVEfmt ")
+	for _, field := range []any{uint32(16), uint16(1), uint16(1), uint32(testRate), uint32(testRate * 2), uint16(2), uint16(16)} {
+		_ = binary.Write(&buf, binary.LittleEndian, field)
+	}
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, dataSize)
+	for range samples {
+		_ = binary.Write(&buf, binary.LittleEndian, amplitude)
+	}
+	return buf.Bytes()
+}
+
+func newTestAudio() (*Audio, *NullOutput) {
+	fsys := fstest.MapFS{
+		"audio/beep.wav":    {Data: wavData(int(testRate), 1<<14)},
+		"audio/song.wav":    {Data: wavData(int(testRate)/2, 1<<14)},
+		"audio/silence.wav": {Data: wavData(int(testRate)/2, 0)},
+	}
+	output := NewNullOutput(testRate, 0)
+	return NewAudio(NewAssetManager(fsys), output), output
+}
+
+func TestAudioBusVolumeAndMute(t *testing.T) {
+	audio, output := newTestAudio()
+	_, err := audio.Play("audio/beep.wav", SFXBus, 1)
+	assert.NoError(t, err)
+
+	samples := output.Advance(time.Millisecond * 10)
+	assert.InDelta(t, testLevel, samples[0][0], 0.01)
+
+	audio.SetBusVolume(SFXBus, 0.5)
+	samples = output.Advance(time.Millisecond * 10)
+	assert.InDelta(t, testLevel/2, samples[0][0], 0.01)
+
+	audio.SetBusMuted(SFXBus, true)
+	samples = output.Advance(time.Millisecond * 10)
+	assert.Equal(t, 0.0, samples[0][0])
+
+	_, err = audio.Play("audio/missing.wav", SFXBus, 1)
+	assert.Error(t, err)
+}
+
+func TestAudioPolyphony(t *testing.T) {
+	audio, output := newTestAudio()
+	audio.SetPolyphony("audio/beep.wav", 2)
+	first, _ := audio.Play("audio/beep.wav", SFXBus, 1)
+	_, _ = audio.Play("audio/beep.wav", SFXBus, 1)
+	_, _ = audio.Play("audio/beep.wav", SFXBus, 1)
+
+	assert.Equal(t, 2, audio.Voices("audio/beep.wav"))
+	assert.False(t, first.Playing())
+	samples := output.Advance(time.Millisecond * 10)
+	assert.InDelta(t, 2*testLevel, samples[0][0], 0.01)
+
+	output.Advance(time.Second)
+	assert.Equal(t, 0, audio.Voices("audio/beep.wav"))
+}
+
+func TestAudioMusicCrossfade(t *testing.T) {
+	audio, output := newTestAudio()
+	audio.PlayMusic(Playlist{Tracks: []string{"audio/song.wav", "audio/silence.wav"}, Crossfade: 100 * time.Millisecond})
+
+	samples := output.Advance(400 * time.Millisecond)
+	assert.InDelta(t, testLevel, samples[testRate.N(200*time.Millisecond)][0], 0.01)
+
+	// Halfway through the fade the song plays at half volume.
+	samples = output.Advance(50 * time.Millisecond)
+	assert.InDelta(t, testLevel/2, samples[len(samples)-1][0], 0.01)
+
+	samples = output.Advance(100 * time.Millisecond)
+	assert.Equal(t, 0.0, samples[len(samples)-1][0])
+
+	audio.PlayMusic(Playlist{Tracks: []string{"audio/song.wav"}, Loop: true})
+	audio.SetBusMuted(MusicBus, true)
+	samples = output.Advance(10 * time.Millisecond)
+	assert.Equal(t, 0.0, samples[0][0])
+}
+
+func TestAudioSystemPlaysRequestsFromBanks(t *testing.T) {
+	audio, _ := newTestAudio()
+	assert.NoError(t, audio.AddBank(SoundBank{Name: "Door", Sounds: []string{"audio/beep.wav", "audio/song.wav"}}))
+	assert.Error(t, audio.AddBank(SoundBank{Name: "Empty"}))
+	audio.StartRecording()
+
+	world := newWorld()
+	world.Audio = audio
+	system := &AudioSystem{}
+	assert.NoError(t, system.Initialize(world))
+
+	request := world.CreateEntity(PlaySound{Name: "Door"})
+	carrier := world.CreateEntity(ComponentA{}, PlaySound{Name: "Door"})
+	assert.NoError(t, system.Update(world))
+
+	assert.Equal(t, []PlaySound{{Name: "Door"}, {Name: "Door"}}, audio.Recorded())
+	assert.Equal(t, 1, audio.Voices("audio/beep.wav"))
+	assert.Equal(t, 1, audio.Voices("audio/song.wav"))
+	_, ok := world.GetEntityComponent(request, reflect.TypeOf(PlaySound{}))
+	assert.False(t, ok)
+	assert.False(t, world.components.entities.Contains(request))
+	assert.True(t, world.components.entities.Contains(carrier))
+
+	world.CreateEntity(PlaySound{Name: "Door", Variation: 2})
+	assert.NoError(t, system.Close())
+	assert.Empty(t, audio.Recorded(), "pending requests are dropped")
+	assert.Equal(t, 1, audio.Voices("audio/song.wav"))
+}
+
+func TestAudioSystemSkipsMissingSources(t *testing.T) {
+	audio, _ := newTestAudio()
+	world := newWorld()
+	world.Audio = audio
+	system := &AudioSystem{}
+	assert.NoError(t, system.Initialize(world))
+
+	world.CreateEntity(AudioSource{Sound: "audio/missing.wav"})
+	world.CreateEntity(AudioSource{Sound: "audio/beep.wav"})
+	assert.NoError(t, system.Update(world))
+	assert.NoError(t, system.Update(world))
+	assert.Equal(t, 1, audio.Voices("audio/beep.wav"))
+	assert.NoError(t, system.Close())
+}
+
+func TestNullOutputDrains(t *testing.T) {
+	output := NewNullOutput(testRate, time.Millisecond)
+	defer output.Close()
+	audio := NewAudio(NewAssetManager(fstest.MapFS{"audio/click.wav": {Data: wavData(int(testRate)/100, 1<<14)}}), output)
+	_, err := audio.Play("audio/click.wav", SFXBus, 1)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool { return audio.Voices("audio/click.wav") == 0 }, time.Second, time.Millisecond)
+}
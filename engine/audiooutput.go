@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gopxl/beep"
+	"github.com/gopxl/beep/speaker"
+)
+
+// AudioOutput is where the mixer's samples end up. The output pulls samples
+// from the streamer passed to Play on its own schedule.
+type AudioOutput interface {
+	SampleRate() beep.SampleRate
+	Play(streamer beep.Streamer)
+	Close() error
+}
+
+// SpeakerOutput plays through the system's audio device.
+type SpeakerOutput struct {
+	rate beep.SampleRate
+}
+
+func NewSpeakerOutput(rate beep.SampleRate, bufferSize time.Duration) (*SpeakerOutput, error) {
+	if err := speaker.Init(rate, rate.N(bufferSize)); err != nil {
+		return nil, err
+	}
+	return &SpeakerOutput{rate: rate}, nil
+}
+
+func (output *SpeakerOutput) SampleRate() beep.SampleRate {
+	return output.rate
+}
+
+func (output *SpeakerOutput) Play(streamer beep.Streamer) {
+	speaker.Play(streamer)
+}
+
+func (output *SpeakerOutput) Close() error {
+	speaker.Clear()
+	return nil
+}
+
+// NullOutput discards all audio.
+type NullOutput struct {
+	mu        sync.Mutex
+	rate      beep.SampleRate
+	streamers []beep.Streamer
+	quit      chan struct{}
+}
+
+// NewNullOutput pulls bufferSize worth of samples every bufferSize, like a
+// device would, so voices still finish. With a bufferSize of zero samples are
+// only pulled when Advance is called, which lets tests step the mixer
+// deterministically.
+func NewNullOutput(rate beep.SampleRate, bufferSize time.Duration) *NullOutput {
+	output := &NullOutput{rate: rate, quit: make(chan struct{})}
+	if bufferSize > 0 {
+		go output.drain(bufferSize, output.quit)
+	}
+	return output
+}
+
+func (output *NullOutput) drain(bufferSize time.Duration, quit chan struct{}) {
+	ticker := time.NewTicker(bufferSize)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-quit:
+			return
+		case <-ticker.C:
+			output.Advance(bufferSize)
+		}
+	}
+}
+
+func (output *NullOutput) SampleRate() beep.SampleRate {
+	return output.rate
+}
+
+func (output *NullOutput) Play(streamer beep.Streamer) {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	output.streamers = append(output.streamers, streamer)
+}
+
+func (output *NullOutput) Close() error {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	output.streamers = nil
+	if output.quit != nil {
+		close(output.quit)
+		output.quit = nil
+	}
+	return nil
+}
+
+// Advance pulls duration worth of samples from everything playing and
+// returns the mixed result.
+func (output *NullOutput) Advance(duration time.Duration) [][2]float64 {
+	output.mu.Lock()
+	defer output.mu.Unlock()
+	samples := make([][2]float64, output.rate.N(duration))
+	buffer := make([][2]float64, len(samples))
+	playing := output.streamers[:0]
+	for _, streamer := range output.streamers {
+		n, ok := streamer.Stream(buffer)
+		for i := range n {
+			samples[i][0] += buffer[i][0]
+			samples[i][1] += buffer[i][1]
+		}
+		if ok {
+			playing = append(playing, streamer)
+		}
+	}
+	output.streamers = playing
+	return samples
+}
@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// Clone returns an independent copy of the current scene's entities,
+// components and resources, with groups for the same matchers and the RNG
+// streams where they are. Systems, the scene stack, the window and audio are
+// not copied; the clone shares the asset manager, prefabs and commands.
+func (world *World) Clone() *World {
+	clone := &World{
+		systems:    map[SystemType][]System{},
+		components: world.components.clone(),
+		groups:     make(map[Matcher]*Group, len(world.groups)),
+		resources:  cloneResources(world.resources),
+		hooks:      make([]worldHook, len(world.hooks)),
+		running:    true,
+		Assets:     world.Assets,
+		Prefabs:    world.Prefabs,
+		Commands:   world.Commands,
+		Logger:     world.Logger,
+		logContext: world.logContext,
+		profiler:   newProfiler(),
+		failures:   map[System]int{},
+		disabled:   map[System]bool{},
+		conditions: map[System][]func(world *World) bool{},
+		removed:    map[System]bool{},
+	}
+	if world.RNG != nil {
+		clone.RNG = world.RNG.clone()
+	}
+	for matcher := range world.groups {
+		clone.groups[matcher] = newGroup(matcher, clone.components)
+	}
+	for i, hook := range world.hooks {
+		clone.hooks[i] = hook.clone()
+	}
+	clone.reindex()
+	return clone
+}
+
+func (storage *ComponentStorage) clone() *ComponentStorage {
+	clone := &ComponentStorage{
+		registry:      maps.Clone(storage.registry),
+		entityIndex:   storage.entityIndex,
+		entities:      storage.entities.clone(nil),
+		componentSets: make([]ComponentSet[any], len(storage.componentSets)),
+		names:         storage.names.clone(),
+	}
+	for idx, set := range storage.componentSets {
+		set.names = clone.names
+		if set.members != nil {
+			set.members = set.members.clone(nil)
+		} else {
+			set.components = set.components.clone(copyComponent)
+		}
+		clone.componentSets[idx] = set
+	}
+	return clone
+}
+
+// clone copies the set, keeping its dense order. Values are passed through
+// copyValue if it is not nil.
+func (set *SparseSet[T]) clone(copyValue func(T) T) *SparseSet[T] {
+	clone := &SparseSet[T]{
+		dense:    slices.Clone(set.dense),
+		sparse:   slices.Clone(set.sparse),
+		capacity: set.capacity,
+		n:        set.n,
+		null:     set.null,
+	}
+	if copyValue != nil {
+		for i := range clone.n {
+			clone.dense[i].value = copyValue(clone.dense[i].value)
+		}
+	}
+	return clone
+}
+
+// copyComponent deep copies component so the copy shares no slices, maps or
+// pointers reachable through exported fields. Unexported fields are copied
+// shallowly.
+func copyComponent(component any) any {
+	if component == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(component)).Interface()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			clone.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return clone
+	case reflect.Array:
+		clone := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			clone.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return clone
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iterator := v.MapRange()
+		for iterator.Next() {
+			clone.SetMapIndex(deepCopy(iterator.Key()), deepCopy(iterator.Value()))
+		}
+		return clone
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.New(v.Type().Elem())
+		clone.Elem().Set(deepCopy(v.Elem()))
+		return clone
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(deepCopy(v.Elem()))
+		return clone
+	case reflect.Struct:
+		clone := reflect.New(v.Type()).Elem()
+		clone.Set(v)
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				clone.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return clone
+	default:
+		return v
+	}
+}
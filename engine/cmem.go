@@ -0,0 +1,15 @@
+package engine
+
+// #include <stdlib.h>
+import "C"
+
+import "unsafe"
+
+// cBytes copies data into C memory, for C libraries that keep the pointer
+// after the call returns. The returned func frees it.
+func cBytes(data []byte) ([]byte, func()) {
+	memory := C.CBytes(data)
+	return unsafe.Slice((*byte)(memory), len(data)), func() {
+		C.free(memory)
+	}
+}
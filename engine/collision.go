@@ -0,0 +1,101 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrCollision  = errors.New("collides")
+	ErrNotOnTiles = errors.New("entity is not on the tilemap")
+)
+
+func init() {
+	RegisterComponent[Collider]("engine.Collider")
+	RegisterComponent[Pushable]("engine.Pushable")
+}
+
+// Collider puts an entity on the collision layers set in Layer and is stopped
+// by the ones set in Mask, through the Collide occupancy rule.
+type Collider struct {
+	Layer uint32
+	Mask  uint32
+}
+
+// Pushable entities are pushed along by World.Move when an entity moves into
+// their cell, if they can move on themselves.
+type Pushable struct{}
+
+// Collision is sent when World.Move can not move an entity into Cell, with
+// the reason CanOccupy gave.
+type Collision struct {
+	Entity uint32
+	Cell   Cell
+	Err    error
+}
+
+var (
+	colliderType = reflect.TypeFor[Collider]()
+	pushableType = reflect.TypeFor[Pushable]()
+)
+
+// Collide is an OccupancyRule that keeps an entity with a Collider out of the
+// cells holding an entity on a layer in its Mask.
+func Collide(world *World, entity uint32, cell Cell) error {
+	component, ok := world.GetEntityComponent(entity, colliderType)
+	if !ok {
+		return nil
+	}
+	mask := component.(Collider).Mask
+	tilemap, err := ResourceMut[Tilemap](world)
+	if err != nil {
+		return err
+	}
+	for _, layer := range tilemap.Layers {
+		other, ok := tilemap.At(layer, cell)
+		if !ok || other == entity {
+			continue
+		}
+		if collider, ok := world.GetEntityComponent(other, colliderType); ok && collider.(Collider).Layer&mask != 0 {
+			return fmt.Errorf("%w with %s", ErrCollision, world.components.names.ref(other))
+		}
+	}
+	return nil
+}
+
+// Move moves entity by offset on the Tilemap if CanOccupy lets it, pushing a
+// Pushable entity in the way first. Otherwise it sends a Collision.
+func (world *World) Move(entity uint32, offset Cell) error {
+	tilemap, err := ResourceMut[Tilemap](world)
+	if err != nil {
+		return err
+	}
+	from, ok := tilemap.Cell(entity)
+	if world.tiles() == nil || !ok {
+		return fmt.Errorf("%w: %s", ErrNotOnTiles, world.components.names.ref(entity))
+	}
+	to := from.Add(offset)
+	err = world.CanOccupy(entity, to)
+	if errors.Is(err, ErrCellOccupied) && world.checkRules(entity, to) == nil {
+		if other, _ := world.occupant(tilemap, entity, to); world.HasComponent(other, pushableType) {
+			if world.CanOccupy(other, to.Add(offset)) == nil {
+				world.place(other, to.Add(offset))
+				err = nil
+			}
+		}
+	}
+	if err != nil {
+		Send(world, Collision{Entity: entity, Cell: to, Err: err})
+		return err
+	}
+	world.place(entity, to)
+	return nil
+}
+
+// place sets entity's position to cell.
+func (world *World) place(entity uint32, cell Cell) {
+	tiles := world.tiles()
+	position, _ := world.GetEntityComponent(entity, tiles.position)
+	world.ReplaceComponent(entity, tiles.place(position, cell))
+}
@@ -0,0 +1,52 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	redBarrier uint32 = 1 << iota
+	blueBarrier
+)
+
+func TestCollide(t *testing.T) {
+	world := newTilemapWorld()
+	world.AddOccupancyRule(Collide)
+	world.CreateEntity(savedPosition{X: 1}, TileLayer("floor"), Collider{Layer: redBarrier})
+	player := world.CreateEntity(savedPosition{}, TileLayer("actors"), Collider{Mask: redBarrier | blueBarrier})
+	summon := world.CreateEntity(savedPosition{Y: 1}, TileLayer("actors"), Collider{Mask: blueBarrier})
+	ghost := world.CreateEntity(savedPosition{Y: 2}, TileLayer("actors"))
+
+	assert.ErrorIs(t, world.CanOccupy(player, Cell{1, 0}), ErrCollision)
+	assert.NoError(t, world.CanOccupy(summon, Cell{1, 0}), "red is not in the summon's mask")
+	assert.NoError(t, world.CanOccupy(ghost, Cell{1, 0}), "entities without a Collider pass")
+	assert.NoError(t, world.CanOccupy(player, Cell{2, 0}))
+}
+
+func TestMove(t *testing.T) {
+	world := newTilemapWorld()
+	world.AddOccupancyRule(Collide)
+	player := world.CreateEntity(savedPosition{}, TileLayer("actors"), Collider{Mask: redBarrier})
+	box := world.CreateEntity(savedPosition{X: 1}, TileLayer("actors"), Pushable{}, Collider{Mask: redBarrier})
+	world.CreateEntity(savedPosition{X: 3}, TileLayer("floor"), Collider{Layer: redBarrier})
+	var collisions EventReader[Collision]
+
+	assert.NoError(t, world.Move(player, Cell{1, 0}))
+	tilemap, _ := ResourceMut[Tilemap](world)
+	cell, _ := tilemap.Cell(player)
+	assert.Equal(t, Cell{1, 0}, cell)
+	cell, _ = tilemap.Cell(box)
+	assert.Equal(t, Cell{2, 0}, cell, "the box is pushed")
+	assert.Empty(t, Read(world, &collisions))
+
+	err := world.Move(player, Cell{1, 0})
+	assert.ErrorIs(t, err, ErrCellOccupied, "the box is stopped by the barrier")
+	cell, _ = tilemap.Cell(box)
+	assert.Equal(t, Cell{2, 0}, cell)
+	assert.Equal(t, []Collision{{Entity: player, Cell: Cell{2, 0}, Err: err}}, Read(world, &collisions))
+
+	assert.ErrorIs(t, world.Move(player, Cell{0, -1}), ErrOutOfBounds)
+	assert.ErrorIs(t, world.Move(world.CreateEntity(), Cell{1, 0}), ErrNotOnTiles)
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownCommand = errors.New("unknown command")
+
+// Command is something that can be typed into the console. Run gets the words
+// after the command's name and returns the text to show.
+type Command struct {
+	Name string
+	// Usage shows the arguments, e.g. "level <number>".
+	Usage string
+	Run   func(world *World, args []string) (string, error)
+	// Complete, if set, returns the candidates for the last of args.
+	Complete func(world *World, args []string) []string
+}
+
+// Commands is a registry of console commands. The engine registers help,
+// timescale and entities; games add their own.
+type Commands struct {
+	mu       sync.RWMutex
+	commands map[string]Command
+}
+
+func NewCommands() *Commands {
+	commands := &Commands{commands: map[string]Command{}}
+	commands.Register(Command{Name: "help", Usage: "help", Run: commands.help})
+	commands.Register(Command{Name: "timescale", Usage: "timescale [scale]", Run: timescaleCommand})
+	commands.Register(Command{Name: "entities", Usage: "entities <component>", Run: entitiesCommand, Complete: completeComponents})
+	return commands
+}
+
+// Register adds command, replacing any command with the same name.
+func (commands *Commands) Register(command Command) {
+	commands.mu.Lock()
+	defer commands.mu.Unlock()
+	commands.commands[command.Name] = command
+}
+
+// Names returns the names of the registered commands, sorted.
+func (commands *Commands) Names() []string {
+	commands.mu.RLock()
+	defer commands.mu.RUnlock()
+	return slices.Sorted(maps.Keys(commands.commands))
+}
+
+// Run splits line into words and runs the command named by the first one.
+func (commands *Commands) Run(world *World, line string) (string, error) {
+	words := strings.Fields(line)
+	if len(words) == 0 {
+		return "", nil
+	}
+	commands.mu.RLock()
+	command, ok := commands.commands[words[0]]
+	commands.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCommand, words[0])
+	}
+	return command.Run(world, words[1:])
+}
+
+// Complete returns the sorted candidates for the last word of line.
+func (commands *Commands) Complete(world *World, line string) []string {
+	words := strings.Fields(line)
+	if len(words) == 0 || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	partial := words[len(words)-1]
+
+	var candidates []string
+	if len(words) == 1 {
+		candidates = commands.Names()
+	} else {
+		commands.mu.RLock()
+		command, ok := commands.commands[words[0]]
+		commands.mu.RUnlock()
+		if !ok || command.Complete == nil {
+			return nil
+		}
+		candidates = command.Complete(world, words[1:])
+	}
+	candidates = slices.DeleteFunc(slices.Clone(candidates), func(candidate string) bool {
+		return !strings.HasPrefix(candidate, partial)
+	})
+	slices.Sort(candidates)
+	return slices.Compact(candidates)
+}
+
+func (commands *Commands) help(*World, []string) (string, error) {
+	commands.mu.RLock()
+	defer commands.mu.RUnlock()
+	usages := make([]string, 0, len(commands.commands))
+	for _, name := range slices.Sorted(maps.Keys(commands.commands)) {
+		usages = append(usages, commands.commands[name].Usage)
+	}
+	return strings.Join(usages, "\n"), nil
+}
+
+func timescaleCommand(world *World, args []string) (string, error) {
+	clock, err := ResourceMut[Time](world)
+	if err != nil {
+		return "", err
+	}
+	if len(args) == 1 {
+		scale, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || scale < 0 {
+			return "", fmt.Errorf("invalid scale %q", args[0])
+		}
+		clock.Scale = scale
+	}
+	return fmt.Sprintf("timescale %g", clock.Scale), nil
+}
+
+// entitiesCommand lists the entities with a component, named by its
+// registered name or Go type.
+func entitiesCommand(world *World, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("usage: entities <component>")
+	}
+	storage := world.components
+	for t, idx := range storage.registry {
+		if componentLabel(t) != args[0] {
+			continue
+		}
+		set := &storage.componentSets[idx]
+		var refs []string
+		for _, entity := range storage.sortedEntities() {
+			if _, ok := set.get(entity); ok {
+				refs = append(refs, storage.names.ref(entity).String())
+			}
+		}
+		return fmt.Sprintf("%d entities: %s", len(refs), strings.Join(refs, ", ")), nil
+	}
+	return "0 entities", nil
+}
+
+func completeComponents(world *World, args []string) []string {
+	if len(args) != 1 {
+		return nil
+	}
+	labels := make([]string, 0, len(world.components.registry))
+	for t := range world.components.registry {
+		labels = append(labels, componentLabel(t))
+	}
+	return labels
+}
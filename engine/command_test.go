@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	world := newWorld()
+	world.CreateEntity(Name("Player"), savedPosition{})
+	world.CreateEntity(savedPosition{X: 1})
+	var colors []string
+	world.Commands.Register(Command{
+		Name:  "give",
+		Usage: "give color <color>",
+		Run: func(world *World, args []string) (string, error) {
+			colors = append(colors, args...)
+			return "", nil
+		},
+		Complete: func(world *World, args []string) []string {
+			if len(args) == 1 {
+				return []string{"color"}
+			}
+			return []string{"Cyan", "Red", "Yellow"}
+		},
+	})
+
+	output, err := world.Commands.Run(world, "  give color  Cyan ")
+	require.NoError(t, err)
+	assert.Empty(t, output)
+	assert.Equal(t, []string{"color", "Cyan"}, colors)
+	_, err = world.Commands.Run(world, "fly")
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+
+	output, err = world.Commands.Run(world, "entities test.Position")
+	require.NoError(t, err)
+	assert.Equal(t, "2 entities: 0 (Player), 1", output)
+
+	output, err = world.Commands.Run(world, "timescale 0.5")
+	require.NoError(t, err)
+	assert.Equal(t, "timescale 0.5", output)
+	clock, _ := Resource[Time](world)
+	assert.Equal(t, 0.5, clock.Scale)
+
+	assert.Equal(t, []string{"entities"}, world.Commands.Complete(world, "en"))
+	assert.Equal(t, []string{"color"}, world.Commands.Complete(world, "give "))
+	assert.Equal(t, []string{"Red"}, world.Commands.Complete(world, "give color R"))
+	assert.Equal(t, []string{"test.Position"}, world.Commands.Complete(world, "entities test"))
+}
+
+func TestConsole(t *testing.T) {
+	world := newWorld()
+	console := &Console{}
+	console.Line = "timesc"
+	console.complete(world)
+	assert.Equal(t, "timescale ", console.Line)
+
+	console.Line += "2"
+	console.run(world)
+	console.Line = "nope"
+	console.run(world)
+	assert.Equal(t, []string{"> timescale 2", "timescale 2", "> nope", `error: unknown command: "nope"`}, console.Output)
+
+	console.browse(-1)
+	console.browse(-1)
+	assert.Equal(t, "timescale 2", console.Line)
+	console.browse(1)
+	console.browse(1)
+	assert.Empty(t, console.Line)
+	assert.True(t, ConsoleClosed(world))
+}
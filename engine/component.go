@@ -15,6 +15,7 @@ type ComponentStorage struct {
 	entityIndex   uint32
 	entities      *SparseSet[any]
 	componentSets []ComponentSet[any]
+	names         *nameIndex
 }
 
 func NewComponentStorage() *ComponentStorage {
@@ -22,6 +23,7 @@ func NewComponentStorage() *ComponentStorage {
 		registry:      map[reflect.Type]int{},
 		entities:      NewSparseSet[any](MaxEntities),
 		componentSets: []ComponentSet[any]{},
+		names:         newNameIndex(),
 	}
 }
 
@@ -33,7 +35,18 @@ func (storage *ComponentStorage) hasComponent(t reflect.Type) bool {
 func (storage *ComponentStorage) registerComponent(t reflect.Type) {
 	idx := len(storage.registry)
 	storage.registry[t] = idx
-	storage.componentSets = append(storage.componentSets, ComponentSet[any]{components: NewSparseSet[any](MaxEntities)})
+	storage.componentSets = append(storage.componentSets, storage.newComponentSet(t))
+}
+
+func (storage *ComponentStorage) newComponentSet(t reflect.Type) ComponentSet[any] {
+	set := ComponentSet[any]{names: storage.names, named: t == nameType}
+	if t.Size() == 0 {
+		set.members = NewSparseSet[struct{}](MaxEntities)
+		set.zero = reflect.Zero(t).Interface()
+	} else {
+		set.components = NewSparseSet[any](MaxEntities)
+	}
+	return set
 }
 
 func (storage *ComponentStorage) getComponentId(t reflect.Type) int {
@@ -50,7 +63,11 @@ func (storage *ComponentStorage) getComponentSet(t reflect.Type) *ComponentSet[a
 func (storage *ComponentStorage) createEntity(components ...any) uint32 {
 	entity := storage.entityIndex
 	storage.entityIndex++
+	storage.insertEntity(entity, components...)
+	return entity
+}
 
+func (storage *ComponentStorage) insertEntity(entity uint32, components ...any) {
 	storage.entities.Insert(entity, entity)
 
 	for _, component := range components {
@@ -61,66 +78,158 @@ func (storage *ComponentStorage) createEntity(components ...any) uint32 {
 		set := storage.getComponentSet(componentType)
 		set.addComponent(entity, component)
 	}
-	return entity
 }
 
 func (storage *ComponentStorage) deleteEntity(entity uint32) {
 	storage.entities.Remove(entity)
 	for _, set := range storage.componentSets {
-		set.components.Remove(entity)
+		set.remove(entity)
+	}
+}
+
+// reviveEntity brings back a deleted entity under its old id.
+func (storage *ComponentStorage) reviveEntity(entity uint32) {
+	storage.entities.Insert(entity, entity)
+	storage.entityIndex = max(storage.entityIndex, entity+1)
+}
+
+// setComponent stores component for entity, or removes the component of
+// type t if component is nil.
+func (storage *ComponentStorage) setComponent(entity uint32, t reflect.Type, component any) {
+	set := storage.getComponentSet(t)
+	set.remove(entity)
+	if component != nil {
+		set.insert(entity, component)
+	}
+}
+
+func (storage *ComponentStorage) componentCount(entity uint32) int {
+	count := 0
+	for _, set := range storage.componentSets {
+		if set.contains(entity) {
+			count++
+		}
 	}
+	return count
 }
 
+// ComponentSet holds the components of one type. Zero-size components are
+// tags: their sets only track which entities have them in members and store
+// no value.
 type ComponentSet[T comparable] struct {
 	components *SparseSet[T]
+	members    *SparseSet[struct{}]
+	zero       T
+	names      *nameIndex
+	named      bool
+}
+
+func (set *ComponentSet[T]) insert(entity uint32, component T) {
+	if set.named {
+		set.names.add(entity, any(component).(Name))
+	}
+	if set.members != nil {
+		set.members.Insert(entity, struct{}{})
+		return
+	}
+	set.components.Insert(entity, component)
+}
+
+func (set *ComponentSet[T]) remove(entity uint32) {
+	if set.named && set.contains(entity) {
+		set.names.remove(entity)
+	}
+	if set.members != nil {
+		set.members.Remove(entity)
+		return
+	}
+	set.components.Remove(entity)
+}
+
+func (set *ComponentSet[T]) get(entity uint32) (T, bool) {
+	if set.members != nil {
+		return set.zero, set.members.Contains(entity)
+	}
+	return set.components.Get(entity)
+}
+
+func (set *ComponentSet[T]) contains(entity uint32) bool {
+	if set.members != nil {
+		return set.members.Contains(entity)
+	}
+	return set.components.Contains(entity)
+}
+
+func (set *ComponentSet[T]) len() uint32 {
+	if set.members != nil {
+		return set.members.Len()
+	}
+	return set.components.Len()
+}
+
+func (set *ComponentSet[T]) copyId() *SparseSet[uint32] {
+	if set.members != nil {
+		return set.members.CopyId()
+	}
+	return set.components.CopyId()
+}
+
+// first returns the entity the set holds first.
+func (set *ComponentSet[T]) first() (uint32, bool) {
+	if set.members != nil {
+		id, _, ok := set.members.Iterator().Next()
+		return id, ok
+	}
+	id, _, ok := set.components.Iterator().Next()
+	return id, ok
 }
 
 func (set *ComponentSet[T]) replaceComponent(entity uint32, component T) {
-	if !set.components.Contains(entity) {
+	if !set.contains(entity) {
 		slog.Error(
 			"Entity not in componentSet",
-			"entity", entity,
+			"entity", set.names.ref(entity),
 			"stack", debug.Stack(),
 		)
 		return
 	}
-	set.components.Remove(entity)
-	set.components.Insert(entity, component)
+	set.remove(entity)
+	set.insert(entity, component)
 }
 
 func (set *ComponentSet[T]) addComponent(entity uint32, component T) {
-	if set.components.Contains(entity) {
+	if set.contains(entity) {
 		slog.Error(
 			"Entity already in componentSet",
-			"entity", entity,
+			"entity", set.names.ref(entity),
 			"stack", debug.Stack(),
 		)
 		return
 	}
-	set.components.Insert(entity, component)
+	set.insert(entity, component)
 }
 
 func (set *ComponentSet[T]) removeEntity(entity uint32) {
-	if !set.components.Contains(entity) {
+	if !set.contains(entity) {
 		slog.Error(
 			"Entity not in componentSet",
-			"entity", entity,
+			"entity", set.names.ref(entity),
 			"stack", debug.Stack(),
 		)
 		panic("Entity not in componentSet")
 	}
-	set.components.Remove(entity)
+	set.remove(entity)
 }
 
 func (set *ComponentSet[T]) getComponent(entity uint32) T {
-	if !set.components.Contains(entity) {
+	if !set.contains(entity) {
 		slog.Error(
 			"Entity not in componentSet",
-			"entity", entity,
+			"entity", set.names.ref(entity),
 			"stack", debug.Stack(),
 		)
 		panic("Entity not in componentSet")
 	}
-	component, _ := set.components.Get(entity)
+	component, _ := set.get(entity)
 	return component
 }
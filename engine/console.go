@@ -0,0 +1,185 @@
+package engine
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// consoleOutputLines is how many lines of output the console keeps.
+const consoleOutputLines = 100
+
+// Console is the resource holding the state of the ConsoleSystem.
+type Console struct {
+	Open    bool
+	Line    string
+	Output  []string
+	History []string
+	// browsing is the position in History shown by the up and down keys.
+	browsing int
+}
+
+// ConsoleOpen reports whether the console is open. Use it with RunIf to keep
+// systems that read the keyboard from reacting to what is typed.
+func ConsoleOpen(world *World) bool {
+	console, err := Resource[Console](world)
+	return err == nil && console.Open
+}
+
+// ConsoleClosed is the opposite of ConsoleOpen, for RunIf.
+func ConsoleClosed(world *World) bool {
+	return !ConsoleOpen(world)
+}
+
+// ConsoleSystem is a command line over the bottom of the window that runs
+// World.Commands. Pressing Key opens and closes it, up and down go through
+// the history and tab completes the word being typed.
+type ConsoleSystem struct {
+	Font string
+	Size int
+	Key  sdl.Keycode
+	// Lines is the number of output lines shown.
+	Lines int
+	font  *Asset[*ttf.Font]
+}
+
+func (s *ConsoleSystem) Close() error {
+	s.font.Release()
+	return nil
+}
+
+func (s *ConsoleSystem) Initialize(world *World) error {
+	font, err := world.Assets.Font(s.Font, s.Size)
+	if err != nil {
+		return err
+	}
+	s.font = font
+	if !HasResource[Console](world) {
+		InsertResource(world, Console{})
+	}
+	return nil
+}
+
+func (s *ConsoleSystem) Update(world *World) error {
+	console, err := ResourceMut[Console](world)
+	if err != nil {
+		return err
+	}
+	input, err := Resource[Input](world)
+	if err != nil {
+		return err
+	}
+	if input.KeyPressed(s.Key) {
+		// The key's character is part of this frame's text.
+		console.Open = !console.Open
+		return nil
+	}
+	if !console.Open {
+		return nil
+	}
+
+	console.Line += input.Text
+	switch {
+	case input.KeyPressed(sdl.K_ESCAPE):
+		console.Open = false
+	case input.KeyPressed(sdl.K_BACKSPACE) && console.Line != "":
+		_, size := utf8.DecodeLastRuneInString(console.Line)
+		console.Line = console.Line[:len(console.Line)-size]
+	case input.KeyPressed(sdl.K_RETURN):
+		console.run(world)
+	case input.KeyPressed(sdl.K_UP):
+		console.browse(-1)
+	case input.KeyPressed(sdl.K_DOWN):
+		console.browse(1)
+	case input.KeyPressed(sdl.K_TAB):
+		console.complete(world)
+	}
+	return s.draw(world, console)
+}
+
+func (console *Console) print(lines ...string) {
+	for _, line := range lines {
+		console.Output = append(console.Output, strings.Split(line, "\n")...)
+	}
+	if extra := len(console.Output) - consoleOutputLines; extra > 0 {
+		console.Output = console.Output[extra:]
+	}
+}
+
+func (console *Console) run(world *World) {
+	line := strings.TrimSpace(console.Line)
+	console.Line = ""
+	if line == "" {
+		return
+	}
+	console.History = append(console.History, line)
+	console.browsing = len(console.History)
+	console.print("> " + line)
+	output, err := world.Commands.Run(world, line)
+	if err != nil {
+		console.print("error: " + err.Error())
+	} else if output != "" {
+		console.print(output)
+	}
+}
+
+func (console *Console) browse(step int) {
+	console.browsing = min(max(console.browsing+step, 0), len(console.History))
+	if console.browsing == len(console.History) {
+		console.Line = ""
+		return
+	}
+	console.Line = console.History[console.browsing]
+}
+
+// complete replaces the word being typed with the candidates' common prefix,
+// and lists the candidates if there are several.
+func (console *Console) complete(world *World) {
+	candidates := world.Commands.Complete(world, console.Line)
+	if len(candidates) == 0 {
+		return
+	}
+	prefix := candidates[0]
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	start := strings.LastIndexAny(console.Line, " \t") + 1
+	console.Line = console.Line[:start] + prefix
+	if len(candidates) == 1 {
+		console.Line += " "
+	} else {
+		console.print(strings.Join(candidates, "  "))
+	}
+}
+
+func (s *ConsoleSystem) draw(world *World, console *Console) error {
+	if world.Window == nil {
+		return nil
+	}
+	surface, err := world.Window.GetSurface()
+	if err != nil {
+		return err
+	}
+	font := s.font.Get()
+	output := console.Output[max(len(console.Output)-s.Lines, 0):]
+	lines := append(output[:len(output):len(output)], "> "+console.Line+"_")
+	height := int32(font.Height())
+	top := surface.H - height*int32(len(lines))
+	_ = surface.FillRect(&sdl.Rect{X: 0, Y: top, W: surface.W, H: surface.H - top}, 0)
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		text, err := font.RenderUTF8Blended(line, sdl.Color{R: 255, G: 255, B: 255, A: 255})
+		if err != nil {
+			return err
+		}
+		_ = text.Blit(nil, surface, &sdl.Rect{X: 0, Y: top + height*int32(i)})
+		text.Free()
+	}
+	return nil
+}
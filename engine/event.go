@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"reflect"
+	"slices"
+)
+
+// EventReader remembers which events of type E a reader has already seen.
+// An event can be read until the end of the frame after it was sent, so
+// every reader sees it once. Changing scenes drops pending events.
+type EventReader[E any] struct {
+	next uint64
+}
+
+type eventQueue interface {
+	swap()
+	clear()
+}
+
+// events holds the events sent in the previous and the current frame. start
+// is the number of events sent before previous[0].
+type events[E any] struct {
+	previous []E
+	current  []E
+	start    uint64
+}
+
+func (queue *events[E]) swap() {
+	queue.start += uint64(len(queue.previous))
+	queue.previous, queue.current = queue.current, queue.previous[:0]
+}
+
+// clear drops every event, keeping the count so readers' cursors stay valid.
+func (queue *events[E]) clear() {
+	queue.start += uint64(len(queue.previous) + len(queue.current))
+	queue.previous, queue.current = queue.previous[:0], queue.current[:0]
+}
+
+// Send queues event for the readers of E.
+func Send[E any](world *World, event E) {
+	queue := eventsOf[E](world)
+	queue.current = append(queue.current, event)
+}
+
+// Read returns the events of type E that reader has not seen yet, oldest
+// first.
+func Read[E any](world *World, reader *EventReader[E]) []E {
+	queue := eventsOf[E](world)
+	first := max(reader.next, queue.start) - queue.start
+	reader.next = queue.start + uint64(len(queue.previous)+len(queue.current))
+	if first >= uint64(len(queue.previous)) {
+		return slices.Clone(queue.current[first-uint64(len(queue.previous)):])
+	}
+	return slices.Concat(queue.previous[first:], queue.current)
+}
+
+func eventsOf[E any](world *World) *events[E] {
+	t := reflect.TypeFor[E]()
+	if queue, ok := world.events[t]; ok {
+		return queue.(*events[E])
+	}
+	if world.events == nil {
+		world.events = map[reflect.Type]eventQueue{}
+	}
+	queue := &events[E]{}
+	world.events[t] = queue
+	return queue
+}
+
+// swapEvents ends the frame for every event type, dropping the events sent
+// in the previous frame.
+func (world *World) swapEvents() {
+	for _, queue := range world.events {
+		queue.swap()
+	}
+}
+
+func (world *World) clearEvents() {
+	for _, queue := range world.events {
+		queue.clear()
+	}
+}
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPressed struct {
+	Symbol rune
+}
+
+type eventSender struct {
+	send []testPressed
+}
+
+func (system *eventSender) Update(world *World) error {
+	for _, event := range system.send {
+		Send(world, event)
+	}
+	system.send = nil
+	return nil
+}
+
+type eventCollector struct {
+	reader EventReader[testPressed]
+	seen   []testPressed
+}
+
+func (system *eventCollector) Update(world *World) error {
+	system.seen = append(system.seen, Read(world, &system.reader)...)
+	return nil
+}
+
+func TestEventsReachReadersBeforeAndAfterSender(t *testing.T) {
+	world := newWorld()
+	before, after := &eventCollector{}, &eventCollector{}
+	sender := &eventSender{}
+	world.AddSystems(before, sender, after)
+
+	sender.send = []testPressed{{Symbol: 'A'}, {Symbol: 'B'}}
+	world.update()
+	assert.Empty(t, before.seen)
+	assert.Equal(t, []testPressed{{Symbol: 'A'}, {Symbol: 'B'}}, after.seen)
+
+	sender.send = []testPressed{{Symbol: 'C'}}
+	world.update()
+	assert.Equal(t, []testPressed{{Symbol: 'A'}, {Symbol: 'B'}}, before.seen)
+	assert.Equal(t, []testPressed{{Symbol: 'A'}, {Symbol: 'B'}, {Symbol: 'C'}}, after.seen, "events are read once")
+
+	world.update()
+	world.update()
+	assert.Equal(t, []testPressed{{Symbol: 'A'}, {Symbol: 'B'}, {Symbol: 'C'}}, before.seen)
+	assert.Len(t, after.seen, 3)
+}
+
+func TestEventsExpireAfterTwoFrames(t *testing.T) {
+	world := newWorld()
+	Send(world, testPressed{Symbol: 'A'})
+	world.update()
+	Send(world, testPressed{Symbol: 'B'})
+	world.update()
+
+	late := EventReader[testPressed]{}
+	assert.Equal(t, []testPressed{{Symbol: 'B'}}, Read(world, &late))
+	assert.Empty(t, Read(world, &late))
+
+	world.clear()
+	Send(world, testPressed{Symbol: 'C'})
+	assert.Equal(t, []testPressed{{Symbol: 'C'}}, Read(world, &late), "readers survive a reset")
+}
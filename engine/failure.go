@@ -0,0 +1,114 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+// FailureAction is what the world does when a system returns an error or
+// panics.
+type FailureAction int
+
+const (
+	// LogAndContinue logs the failure and keeps running the system.
+	LogAndContinue FailureAction = iota
+	// DisableSystem stops running the system once it has failed more than
+	// MaxFailures times.
+	DisableSystem
+	// StopWorld makes Simulate return the failure after the current frame.
+	StopWorld
+)
+
+type FailurePolicy struct {
+	Action      FailureAction
+	MaxFailures int
+}
+
+// FailurePolicySystem is implemented by systems that need a different
+// policy than the world's FailurePolicy.
+type FailurePolicySystem interface {
+	System
+	FailurePolicy() FailurePolicy
+}
+
+// SystemError is a failure of a system, with the frame it happened in.
+// Stack is set if the system panicked.
+type SystemError struct {
+	System string
+	Phase  SystemType
+	Frame  uint64
+	Err    error
+	Stack  []byte
+}
+
+func (err *SystemError) Error() string {
+	phase := "updating"
+	if err.Phase == Initialize {
+		phase = "initializing"
+	}
+	return fmt.Sprintf("%s %s in frame %d: %v", phase, err.System, err.Frame, err.Err)
+}
+
+func (err *SystemError) Unwrap() error {
+	return err.Err
+}
+
+// run runs one phase of system, applying the failure policy if it fails.
+func (world *World) run(system System, phase SystemType, f func() error) {
+	defer world.logContext.running(system)()
+	if err := world.recovered(f); err != nil {
+		systemErr := &SystemError{System: reflect.TypeOf(system).String(), Phase: phase, Frame: world.profiler.frames, Err: err}
+		var panicked *panicError
+		if errors.As(err, &panicked) {
+			systemErr.Stack = panicked.stack
+		}
+		world.fail(system, systemErr)
+	}
+}
+
+type panicError struct {
+	value any
+	stack []byte
+}
+
+func (err *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", err.value)
+}
+
+func (world *World) recovered(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &panicError{value: r, stack: debug.Stack()}
+		}
+	}()
+	return f()
+}
+
+func (world *World) fail(system System, err *SystemError) {
+	policy := world.FailurePolicy
+	if custom, ok := system.(FailurePolicySystem); ok {
+		policy = custom.FailurePolicy()
+	}
+	attrs := []any{"error", err.Err}
+	if err.Stack != nil {
+		attrs = append(attrs, "stack", string(err.Stack))
+	}
+
+	switch policy.Action {
+	case DisableSystem:
+		world.failures[system]++
+		if world.failures[system] > policy.MaxFailures {
+			world.Logger.Error("System failed and was disabled", attrs...)
+			world.disabled[system] = true
+			return
+		}
+	case StopWorld:
+		world.Logger.Error("System failed and stopped the world", attrs...)
+		world.stopErrors = append(world.stopErrors, err)
+		world.running = false
+		return
+	}
+	world.Logger.Error("System failed", attrs...)
+}
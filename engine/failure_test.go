@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errBroken = errors.New("broken")
+
+type failingSystem struct {
+	policy  *FailurePolicy
+	panics  bool
+	updates int
+}
+
+func (system *failingSystem) FailurePolicy() FailurePolicy {
+	if system.policy == nil {
+		return FailurePolicy{}
+	}
+	return *system.policy
+}
+
+func (system *failingSystem) Update(world *World) error {
+	system.updates++
+	if system.panics {
+		var missing map[string]int
+		missing["boom"]++
+	}
+	return errBroken
+}
+
+func TestFailurePolicies(t *testing.T) {
+	world := newWorld()
+	logging := &failingSystem{}
+	disabled := &failingSystem{policy: &FailurePolicy{Action: DisableSystem, MaxFailures: 2}, panics: true}
+	world.AddSystems(logging, disabled)
+	for range 5 {
+		world.update()
+	}
+	assert.Equal(t, 5, logging.updates)
+	assert.Equal(t, 3, disabled.updates, "disabled after failing more than MaxFailures times")
+	assert.True(t, world.running)
+
+	stopping := &failingSystem{policy: &FailurePolicy{Action: StopWorld}}
+	world.AddSystems(stopping)
+	world.update()
+	assert.False(t, world.running)
+
+	err := errors.Join(world.stopErrors...)
+	require.ErrorIs(t, err, errBroken)
+	var systemErr *SystemError
+	require.ErrorAs(t, err, &systemErr)
+	assert.Equal(t, "*engine.failingSystem", systemErr.System)
+	assert.Equal(t, uint64(5), systemErr.Frame)
+	assert.EqualError(t, systemErr, "updating *engine.failingSystem in frame 5: broken")
+}
+
+func TestPanicsBecomeErrors(t *testing.T) {
+	world := newWorld()
+	world.AddSystems(&failingSystem{panics: true, policy: &FailurePolicy{Action: StopWorld}})
+	world.update()
+
+	require.Len(t, world.stopErrors, 1)
+	var systemErr *SystemError
+	require.ErrorAs(t, world.stopErrors[0], &systemErr)
+	assert.ErrorContains(t, systemErr, "panic: assignment to entry in nil map")
+	assert.Contains(t, string(systemErr.Stack), "failingSystem")
+}
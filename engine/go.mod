@@ -3,12 +3,15 @@ module github.com/lakrsv/parkour-engine/engine
 go 1.24.1
 
 require (
+	github.com/gopxl/beep v1.4.1
 	github.com/stretchr/testify v1.9.0
 	github.com/veandco/go-sdl2 v0.4.40
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/hajimehoshi/go-mp3 v0.3.4 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
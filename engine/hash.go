@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"reflect"
+)
+
+// Hash returns a hash of every entity with its registered components and of
+// the registered resources. Worlds with the same entities and values hash the
+// same no matter in which order the entities and components were added.
+// Values that cannot be encoded, such as ones holding funcs, only contribute
+// their name.
+func (world *World) Hash() uint64 {
+	storage := world.components
+	sets := storage.namedSets()
+	entities := storage.sortedEntities()
+
+	hash := fnv.New64a()
+	encoder := &binaryEncoder{}
+	for _, entity := range entities {
+		encoder.buf = binary.AppendUvarint(encoder.buf[:0], uint64(entity))
+		for _, set := range sets {
+			component, ok := set.set.get(entity)
+			if !ok {
+				continue
+			}
+			encoder.string(set.name)
+			mark := len(encoder.buf)
+			if err := encoder.value(reflect.ValueOf(component)); err != nil {
+				encoder.buf = encoder.buf[:mark]
+			}
+		}
+		_, _ = hash.Write(encoder.buf)
+	}
+	for _, resource := range world.namedResources() {
+		encoder.buf = encoder.buf[:0]
+		encoder.string(resource.name)
+		mark := len(encoder.buf)
+		if err := encoder.value(reflect.ValueOf(resource.value)); err != nil {
+			encoder.buf = encoder.buf[:mark]
+		}
+		_, _ = hash.Write(encoder.buf)
+	}
+	return hash.Sum64()
+}
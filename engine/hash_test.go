@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// walkSystem moves every savedPosition by the frame's input, spawns an
+// entity every third frame and deletes the oldest one every fifth, so the
+// sparse sets get shuffled the same way in every world it runs in.
+type walkSystem struct {
+	group  *Group
+	inputs []savedPosition
+	frame  int
+}
+
+func (system *walkSystem) Initialize(world *World) error {
+	system.group = world.GetGroup(savedPositionMatcher)
+	return nil
+}
+
+func (system *walkSystem) Update(world *World) error {
+	input := system.inputs[system.frame%len(system.inputs)]
+	for _, entity := range system.group.GetEntities() {
+		position, _ := positionOf(world, entity)
+		world.ReplaceComponent(entity, savedPosition{X: position.X + input.X, Y: position.Y + input.Y})
+	}
+	if system.frame%3 == 0 {
+		world.CreateEntity(savedPosition{X: system.frame}, savedInventory{Counts: map[string]int{"frame": system.frame}})
+	}
+	if system.frame%5 == 0 {
+		entities := system.group.GetEntities()
+		oldest := entities[0]
+		for _, entity := range entities {
+			oldest = min(oldest, entity)
+		}
+		world.DeleteEntity(oldest)
+	}
+	system.frame++
+	return nil
+}
+
+func TestCloneSimulatesIdentically(t *testing.T) {
+	inputs := []savedPosition{{X: 1}, {Y: 1}, {X: -1}, {X: 1, Y: -1}}
+	world := newWorld()
+	world.CreateEntity(savedPosition{X: 1, Y: 1}, savedTag{})
+	world.CreateEntity(savedPosition{X: 2, Y: 2}, savedInventory{Items: []string{"key"}})
+	world.CreateEntity(savedPosition{X: 3, Y: 3})
+
+	clone := world.Clone()
+	assert.Equal(t, world.Hash(), clone.Hash())
+
+	world.AddSystems(&walkSystem{inputs: inputs})
+	clone.AddSystems(&walkSystem{inputs: inputs})
+	world.initialize()
+	clone.initialize()
+	for frame := range 60 {
+		world.update()
+		clone.update()
+		assert.Equal(t, world.Hash(), clone.Hash(), "frame %d", frame)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	world := newWorld()
+	entity := world.CreateEntity(savedPosition{}, savedInventory{Items: []string{"key"}, Owner: &savedPosition{X: 1}})
+	hash := world.Hash()
+
+	clone := world.Clone()
+	inventory, _ := clone.GetEntityComponent(entity, reflect.TypeOf(savedInventory{}))
+	inventory.(savedInventory).Items[0] = "map"
+	inventory.(savedInventory).Owner.X = 2
+	clone.DeleteEntity(clone.CreateEntity(savedTag{}))
+	clone.ReplaceComponent(entity, savedPosition{X: 5})
+
+	assert.Equal(t, hash, world.Hash())
+	assert.NotEqual(t, hash, clone.Hash())
+	assert.ElementsMatch(t, []uint32{entity}, world.GetGroup(savedPositionMatcher).GetEntities())
+	position, _ := positionOf(world, entity)
+	assert.Equal(t, savedPosition{}, position)
+}
+
+func TestHashIgnoresInsertionOrder(t *testing.T) {
+	first := newWorld()
+	a := first.CreateEntity(savedPosition{X: 1})
+	b := first.CreateEntity(savedPosition{X: 2})
+	first.AddComponent(a, savedInventory{Counts: map[string]int{"a": 1, "b": 2, "c": 3}})
+
+	second := newWorld()
+	second.CreateEntity()
+	second.CreateEntity()
+	second.AddComponent(b, savedPosition{X: 2})
+	second.AddComponent(a, savedInventory{Counts: map[string]int{"c": 3, "b": 2, "a": 1}})
+	second.AddComponent(a, savedPosition{X: 1})
+	second.CreateEntity(unsavedRequest{Name: "not hashed"})
+	second.DeleteEntity(2)
+
+	assert.Equal(t, first.Hash(), second.Hash())
+	second.ReplaceComponent(b, savedPosition{X: 3})
+	assert.NotEqual(t, first.Hash(), second.Hash())
+}
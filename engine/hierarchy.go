@@ -0,0 +1,139 @@
+package engine
+
+import (
+	"reflect"
+	"slices"
+)
+
+func init() {
+	RegisterComponent[Parent]("engine.Parent")
+	RegisterComponent[Children]("engine.Children")
+}
+
+// Parent is the entity an entity is attached to. Set it with SetParent.
+type Parent struct {
+	Entity uint32
+}
+
+// Children are the entities attached to an entity, in the order they were
+// attached. Deleting an entity deletes its children with it.
+type Children struct {
+	Entities []uint32
+}
+
+// Relative keeps an entity's T component at Offset from its parent's T.
+type Relative[T any] struct {
+	Offset T
+}
+
+type follower struct {
+	target   reflect.Type
+	relative reflect.Type
+	combine  func(parent, relative any) any
+}
+
+// FollowParent makes entities with a Relative[T] follow their parent, setting
+// their T to combine(parent, offset).
+func FollowParent[T any](world *World, combine func(parent, offset T) T) {
+	f := &follower{
+		target:   reflect.TypeFor[T](),
+		relative: reflect.TypeFor[Relative[T]](),
+		combine: func(parent, relative any) any {
+			return combine(parent.(T), relative.(Relative[T]).Offset)
+		},
+	}
+	setHook(world, f, func(other *follower) bool { return other.target == f.target })
+}
+
+// SetParent attaches child to parent, detaching it from its previous parent.
+func (world *World) SetParent(child, parent uint32) {
+	for ancestor, ok := parent, true; ok; ancestor, ok = world.GetParent(ancestor) {
+		if ancestor == child {
+			world.EntityLogger(child).Error("Entity can not be its own ancestor", "parent", world.components.names.ref(parent))
+			return
+		}
+	}
+	world.RemoveParent(child)
+	world.ReplaceComponent(child, Parent{Entity: parent})
+	world.ReplaceComponent(parent, Children{Entities: append(world.GetChildren(parent), child)})
+	for _, hook := range world.hooks {
+		if f, ok := hook.(*follower); ok {
+			world.followParent(child, f)
+		}
+	}
+}
+
+// RemoveParent detaches entity from its parent, if it has one.
+func (world *World) RemoveParent(entity uint32) {
+	parent, ok := world.GetParent(entity)
+	if !ok {
+		return
+	}
+	world.RemoveComponent(entity, reflect.TypeOf(Parent{}))
+	children := world.GetChildren(parent)
+	children = slices.DeleteFunc(children, func(child uint32) bool { return child == entity })
+	if len(children) == 0 {
+		world.RemoveComponent(parent, reflect.TypeOf(Children{}))
+		return
+	}
+	world.ReplaceComponent(parent, Children{Entities: children})
+}
+
+func (world *World) GetParent(entity uint32) (uint32, bool) {
+	component, ok := world.GetEntityComponent(entity, reflect.TypeOf(Parent{}))
+	if !ok {
+		return 0, false
+	}
+	return component.(Parent).Entity, true
+}
+
+// GetChildren returns a copy of the entities attached to entity.
+func (world *World) GetChildren(entity uint32) []uint32 {
+	component, ok := world.GetEntityComponent(entity, reflect.TypeOf(Children{}))
+	if !ok {
+		return nil
+	}
+	return slices.Clone(component.(Children).Entities)
+}
+
+func (world *World) deleteChildren(entity uint32) {
+	for _, child := range world.GetChildren(entity) {
+		world.DeleteEntity(child)
+	}
+	world.RemoveParent(entity)
+}
+
+func (f *follower) changed(world *World, entity uint32, t reflect.Type) {
+	switch t {
+	case f.relative:
+		world.followParent(entity, f)
+	case f.target:
+		for _, child := range world.GetChildren(entity) {
+			world.followParent(child, f)
+		}
+	}
+}
+
+func (f *follower) deleted(*World, uint32) {}
+
+func (f *follower) reindex(*World) {}
+
+func (f *follower) clone() worldHook {
+	return f
+}
+
+func (world *World) followParent(entity uint32, f *follower) {
+	relative, ok := world.GetEntityComponent(entity, f.relative)
+	if !ok {
+		return
+	}
+	parent, ok := world.GetParent(entity)
+	if !ok {
+		return
+	}
+	target, ok := world.GetEntityComponent(parent, f.target)
+	if !ok {
+		return
+	}
+	world.ReplaceComponent(entity, f.combine(target, relative))
+}
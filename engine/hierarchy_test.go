@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteEntityDeletesChildren(t *testing.T) {
+	world := newWorld()
+	player := world.CreateEntity(savedPosition{})
+	indicator := world.CreateEntity(savedPosition{})
+	label := world.CreateEntity(savedTag{})
+	sparkle := world.CreateEntity(savedPosition{})
+	world.SetParent(indicator, player)
+	world.SetParent(label, player)
+	world.SetParent(sparkle, indicator)
+	group := world.GetGroup(savedPositionMatcher)
+
+	assert.Equal(t, []uint32{indicator, label}, world.GetChildren(player))
+	parent, ok := world.GetParent(sparkle)
+	assert.True(t, ok)
+	assert.Equal(t, indicator, parent)
+
+	history := world.History()
+	history.BeginTurn()
+	world.DeleteEntity(player)
+	assert.Empty(t, group.GetEntities())
+	_, ok = world.GetEntityComponent(label, reflect.TypeOf(savedTag{}))
+	assert.False(t, ok)
+
+	assert.True(t, history.Undo())
+	assert.ElementsMatch(t, []uint32{player, indicator, sparkle}, group.GetEntities())
+	assert.Equal(t, []uint32{indicator, label}, world.GetChildren(player))
+}
+
+func TestSetParent(t *testing.T) {
+	world := newWorld()
+	first := world.CreateEntity()
+	second := world.CreateEntity()
+	child := world.CreateEntity()
+
+	world.SetParent(child, first)
+	world.SetParent(child, second)
+	assert.Empty(t, world.GetChildren(first))
+	_, ok := world.GetEntityComponent(first, reflect.TypeOf(Children{}))
+	assert.False(t, ok)
+	assert.Equal(t, []uint32{child}, world.GetChildren(second))
+
+	world.SetParent(second, child)
+	_, ok = world.GetParent(second)
+	assert.False(t, ok, "cycles are refused")
+
+	world.DeleteEntity(child)
+	assert.Empty(t, world.GetChildren(second))
+}
+
+func TestFollowParent(t *testing.T) {
+	world := newWorld()
+	FollowParent(world, func(parent, offset savedPosition) savedPosition {
+		return savedPosition{X: parent.X + offset.X, Y: parent.Y + offset.Y}
+	})
+	player := world.CreateEntity(savedPosition{X: 1, Y: 1})
+	indicator := world.CreateEntity(Relative[savedPosition]{Offset: savedPosition{X: 1}})
+	sparkle := world.CreateEntity(Relative[savedPosition]{Offset: savedPosition{Y: -1}})
+	world.SetParent(indicator, player)
+	world.SetParent(sparkle, indicator)
+
+	position, _ := positionOf(world, sparkle)
+	assert.Equal(t, savedPosition{X: 2, Y: 0}, position)
+
+	world.ReplaceComponent(player, savedPosition{X: 5, Y: 5})
+	position, _ = positionOf(world, indicator)
+	assert.Equal(t, savedPosition{X: 6, Y: 5}, position)
+	position, _ = positionOf(world, sparkle)
+	assert.Equal(t, savedPosition{X: 6, Y: 4}, position)
+
+	world.ReplaceComponent(indicator, Relative[savedPosition]{Offset: savedPosition{Y: 1}})
+	position, _ = positionOf(world, sparkle)
+	assert.Equal(t, savedPosition{X: 5, Y: 5}, position)
+}
@@ -0,0 +1,240 @@
+package engine
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+const defaultHistoryLimit = 256
+
+type historyOpKind int
+
+const (
+	entityCreated historyOpKind = iota
+	entityDeleted
+	componentChanged
+)
+
+// historyOp is one change to the world. For component changes a nil before
+// or after means the component was absent.
+type historyOp struct {
+	kind          historyOpKind
+	entity        uint32
+	componentType reflect.Type
+	before, after any
+}
+
+type historyTurn struct {
+	ops []historyOp
+	// began is set for turns started by BeginTurn. Changes made after an
+	// undo or redo until the next BeginTurn are recorded in a turn without it.
+	began bool
+}
+
+// History records the changes made to the world in turns, such as a player's
+// move and everything that follows from it, so whole turns can be undone and
+// redone. Nothing is recorded until the first BeginTurn.
+//
+// Only changes made through the World are seen; slices or maps changed in
+// place are not.
+type History struct {
+	world    *World
+	undo     []*historyTurn
+	redo     []*historyTurn
+	current  *historyTurn
+	ignored  map[reflect.Type]bool
+	limit    int
+	applying bool
+}
+
+// History returns the history of the current scene.
+func (world *World) History() *History {
+	if world.history == nil {
+		world.history = &History{
+			world: world,
+			ignored: map[reflect.Type]bool{
+				reflect.TypeOf(PlaySound{}): true,
+			},
+			limit: defaultHistoryLimit,
+		}
+	}
+	return world.history
+}
+
+// Ignore stops recording changes to components of the given types. Sound
+// requests are ignored by default.
+func (history *History) Ignore(types ...reflect.Type) {
+	for _, t := range types {
+		history.ignored[t] = true
+	}
+}
+
+// SetLimit sets how many turns can be undone. The oldest turns are forgotten
+// first.
+func (history *History) SetLimit(limit int) {
+	history.limit = max(limit, 1)
+	history.trim()
+}
+
+// BeginTurn ends the current turn and starts recording a new one. Beginning
+// a turn forgets the turns that could be redone.
+func (history *History) BeginTurn() {
+	history.finish()
+	history.redo = nil
+	history.current = &historyTurn{began: true}
+}
+
+// Undo reverts the world to how it was before the last turn began. It
+// returns false if there is nothing to undo.
+func (history *History) Undo() bool {
+	turn := history.current
+	history.current = &historyTurn{}
+	if turn != nil && len(turn.ops) > 0 {
+		if turn.began {
+			history.push(turn)
+		} else {
+			history.revert(turn)
+		}
+	}
+	if len(history.undo) == 0 {
+		return false
+	}
+	turn = history.undo[len(history.undo)-1]
+	history.undo = history.undo[:len(history.undo)-1]
+	history.revert(turn)
+	history.redo = append(history.redo, turn)
+	return true
+}
+
+// Redo replays the last undone turn. Changes made since the undo are
+// discarded first. It returns false if there is nothing to redo.
+func (history *History) Redo() bool {
+	if len(history.redo) == 0 {
+		return false
+	}
+	if history.current != nil {
+		history.revert(history.current)
+	}
+	turn := history.redo[len(history.redo)-1]
+	history.redo = history.redo[:len(history.redo)-1]
+	history.apply(turn)
+	history.undo = append(history.undo, turn)
+	history.current = &historyTurn{}
+	return true
+}
+
+func (history *History) CanUndo() bool {
+	return len(history.undo) > 0 || (history.current != nil && len(history.current.ops) > 0)
+}
+
+func (history *History) CanRedo() bool {
+	return len(history.redo) > 0
+}
+
+// Clear forgets every turn and stops recording until the next BeginTurn.
+func (history *History) Clear() {
+	history.undo = nil
+	history.redo = nil
+	history.current = nil
+}
+
+// finish stores the current turn. Changes recorded after an undo or redo
+// happened on top of the last turn, so they are folded into it.
+func (history *History) finish() {
+	turn := history.current
+	history.current = nil
+	if turn == nil || len(turn.ops) == 0 {
+		return
+	}
+	if turn.began {
+		history.push(turn)
+		return
+	}
+	if len(history.undo) > 0 {
+		last := history.undo[len(history.undo)-1]
+		last.ops = append(last.ops, turn.ops...)
+	}
+}
+
+func (history *History) push(turn *historyTurn) {
+	history.undo = append(history.undo, turn)
+	history.trim()
+}
+
+func (history *History) trim() {
+	if extra := len(history.undo) - history.limit; extra > 0 {
+		history.undo = slices.Delete(history.undo, 0, extra)
+	}
+}
+
+func (history *History) recording() bool {
+	return history != nil && history.current != nil && !history.applying
+}
+
+func (history *History) record(op historyOp) {
+	history.current.ops = append(history.current.ops, op)
+}
+
+func (history *History) recordComponent(entity uint32, t reflect.Type, before, after any) {
+	if history.ignored[t] {
+		return
+	}
+	history.record(historyOp{
+		kind:          componentChanged,
+		entity:        entity,
+		componentType: t,
+		before:        copyComponent(before),
+		after:         copyComponent(after),
+	})
+}
+
+func (history *History) revert(turn *historyTurn) {
+	history.applying = true
+	defer func() { history.applying = false }()
+	entities := map[uint32]bool{}
+	for i := len(turn.ops) - 1; i >= 0; i-- {
+		op := turn.ops[i]
+		entities[op.entity] = true
+		switch op.kind {
+		case entityCreated:
+			history.world.components.deleteEntity(op.entity)
+		case entityDeleted:
+			history.world.components.reviveEntity(op.entity)
+		case componentChanged:
+			history.world.components.setComponent(op.entity, op.componentType, copyComponent(op.before))
+		}
+	}
+	history.world.evaluateGroups(entities)
+	history.world.reindex()
+}
+
+func (history *History) apply(turn *historyTurn) {
+	history.applying = true
+	defer func() { history.applying = false }()
+	entities := map[uint32]bool{}
+	for _, op := range turn.ops {
+		entities[op.entity] = true
+		switch op.kind {
+		case entityCreated:
+			history.world.components.reviveEntity(op.entity)
+		case entityDeleted:
+			history.world.components.deleteEntity(op.entity)
+		case componentChanged:
+			history.world.components.setComponent(op.entity, op.componentType, copyComponent(op.after))
+		}
+	}
+	history.world.evaluateGroups(entities)
+	history.world.reindex()
+}
+
+func (world *World) evaluateGroups(entities map[uint32]bool) {
+	var wg sync.WaitGroup
+	for entity := range entities {
+		wg.Add(len(world.groups))
+		for _, group := range world.groups {
+			group.EvaluateEntity(entity, world.components, &wg)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func positionOf(world *World, entity uint32) (savedPosition, bool) {
+	component, ok := world.GetEntityComponent(entity, reflect.TypeOf(savedPosition{}))
+	if !ok {
+		return savedPosition{}, false
+	}
+	return component.(savedPosition), true
+}
+
+func TestHistoryUndoRedo(t *testing.T) {
+	world := newWorld()
+	player := world.CreateEntity(savedPosition{X: 0, Y: 0})
+	door := world.CreateEntity(savedTag{}, savedInventory{Items: []string{"closed"}})
+	group := world.GetGroup(savedPositionMatcher)
+	history := world.History()
+
+	history.BeginTurn()
+	world.ReplaceComponent(player, savedPosition{X: 1, Y: 0})
+	world.RemoveComponent(door, reflect.TypeOf(savedTag{}))
+	world.ReplaceComponent(door, savedInventory{Items: []string{"open"}})
+	summon := world.CreateEntity(savedPosition{X: 2, Y: 0})
+	world.CreateEntity(PlaySound{Name: "ignored"})
+
+	history.BeginTurn()
+	world.ReplaceComponent(summon, savedPosition{X: 3, Y: 0})
+	world.DeleteEntity(player)
+
+	assert.True(t, history.Undo())
+	position, _ := positionOf(world, player)
+	assert.Equal(t, savedPosition{X: 1, Y: 0}, position, "deleted entities come back")
+	position, _ = positionOf(world, summon)
+	assert.Equal(t, savedPosition{X: 2, Y: 0}, position)
+
+	assert.True(t, history.Undo())
+	position, _ = positionOf(world, player)
+	assert.Equal(t, savedPosition{X: 0, Y: 0}, position)
+	_, ok := positionOf(world, summon)
+	assert.False(t, ok, "created entities are deleted")
+	assert.ElementsMatch(t, []uint32{player}, group.GetEntities())
+	_, ok = world.GetEntityComponent(door, reflect.TypeOf(savedTag{}))
+	assert.True(t, ok)
+	inventory, _ := world.GetEntityComponent(door, reflect.TypeOf(savedInventory{}))
+	assert.Equal(t, []string{"closed"}, inventory.(savedInventory).Items)
+	assert.False(t, history.Undo())
+
+	assert.True(t, history.Redo())
+	position, _ = positionOf(world, summon)
+	assert.Equal(t, savedPosition{X: 2, Y: 0}, position, "redone entities keep their ids")
+	assert.ElementsMatch(t, []uint32{player, summon}, group.GetEntities())
+
+	assert.True(t, history.Redo())
+	_, ok = positionOf(world, player)
+	assert.False(t, ok)
+	assert.False(t, history.Redo())
+}
+
+func TestHistoryChangesAfterUndo(t *testing.T) {
+	world := newWorld()
+	summon := world.CreateEntity(savedPosition{X: 0, Y: 0})
+	history := world.History()
+
+	history.BeginTurn()
+	world.ReplaceComponent(summon, savedPosition{X: 1, Y: 0})
+	history.BeginTurn()
+	world.ReplaceComponent(summon, savedPosition{X: 2, Y: 0})
+	assert.True(t, history.Undo())
+
+	// Things keep moving after an undo. Redo discards those changes.
+	world.ReplaceComponent(summon, savedPosition{X: 1, Y: 1})
+	assert.True(t, history.Redo())
+	position, _ := positionOf(world, summon)
+	assert.Equal(t, savedPosition{X: 2, Y: 0}, position)
+
+	// A new turn folds them into the turn they happened after.
+	assert.True(t, history.Undo())
+	world.ReplaceComponent(summon, savedPosition{X: 1, Y: 1})
+	history.BeginTurn()
+	world.ReplaceComponent(summon, savedPosition{X: 1, Y: 2})
+	assert.False(t, history.CanRedo())
+	assert.True(t, history.Undo())
+	position, _ = positionOf(world, summon)
+	assert.Equal(t, savedPosition{X: 1, Y: 1}, position)
+	assert.True(t, history.Undo())
+	position, _ = positionOf(world, summon)
+	assert.Equal(t, savedPosition{X: 0, Y: 0}, position)
+}
+
+func TestHistoryLimit(t *testing.T) {
+	world := newWorld()
+	entity := world.CreateEntity(savedPosition{})
+	history := world.History()
+	history.SetLimit(2)
+	for x := 1; x <= 3; x++ {
+		history.BeginTurn()
+		world.ReplaceComponent(entity, savedPosition{X: x})
+	}
+	assert.True(t, history.Undo())
+	assert.True(t, history.Undo())
+	assert.False(t, history.Undo())
+	position, _ := positionOf(world, entity)
+	assert.Equal(t, savedPosition{X: 1}, position)
+}
@@ -0,0 +1,54 @@
+package engine
+
+import "reflect"
+
+// worldHook keeps state derived from components, such as the Tilemap, in step
+// with the changes made through the World.
+type worldHook interface {
+	changed(world *World, entity uint32, t reflect.Type)
+	deleted(world *World, entity uint32)
+	// reindex rebuilds the state after Restore, History and scene changes.
+	reindex(world *World)
+	clone() worldHook
+}
+
+// findHook returns the first hook of type H that match accepts.
+func findHook[H worldHook](world *World, match func(H) bool) (H, bool) {
+	for _, hook := range world.hooks {
+		if h, ok := hook.(H); ok && match(h) {
+			return h, true
+		}
+	}
+	var zero H
+	return zero, false
+}
+
+// setHook replaces the first hook of type H that match accepts with hook, or
+// adds hook after the others.
+func setHook[H worldHook](world *World, hook H, match func(H) bool) {
+	for i, other := range world.hooks {
+		if h, ok := other.(H); ok && match(h) {
+			world.hooks[i] = hook
+			return
+		}
+	}
+	world.hooks = append(world.hooks, hook)
+}
+
+func (world *World) changed(entity uint32, t reflect.Type) {
+	for _, hook := range world.hooks {
+		hook.changed(world, entity, t)
+	}
+}
+
+func (world *World) deleted(entity uint32) {
+	for _, hook := range world.hooks {
+		hook.deleted(world, entity)
+	}
+}
+
+func (world *World) reindex() {
+	for _, hook := range world.hooks {
+		hook.reindex(world)
+	}
+}
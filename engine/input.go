@@ -2,11 +2,14 @@ package engine
 
 import "github.com/veandco/go-sdl2/sdl"
 
-type InputComponent struct {
+// Input is the resource holding the keys pressed and released this frame,
+// and the text typed.
+type Input struct {
 	KeyState map[sdl.Keycode]bool
+	Text     string
 }
 
-func (c *InputComponent) KeyPressed(key sdl.Keycode) bool {
+func (c *Input) KeyPressed(key sdl.Keycode) bool {
 	state, ok := c.KeyState[key]
 	if ok {
 		return state
@@ -14,7 +17,7 @@ func (c *InputComponent) KeyPressed(key sdl.Keycode) bool {
 	return false
 }
 
-func (c *InputComponent) KeyReleased(key sdl.Keycode) bool {
+func (c *Input) KeyReleased(key sdl.Keycode) bool {
 	state, ok := c.KeyState[key]
 	if ok {
 		return !state
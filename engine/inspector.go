@@ -0,0 +1,385 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Inspector serves the world's entities, groups and systems as JSON for
+// debugging. Requests are answered on the main loop between frames, so they
+// see and change the world the same way systems do.
+//
+//	GET    /entities                          ids, names and component names
+//	GET    /entities/{id}                     an entity's components
+//	PUT    /entities/{id}/components/{name}   replace (or add) a component
+//	DELETE /entities/{id}                     delete an entity
+//	GET    /groups                            matchers and member counts
+//	GET    /systems                           systems with timings
+type Inspector struct {
+	world     *World
+	server    *http.Server
+	listener  net.Listener
+	requests  chan func()
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// ErrInspectorNotLocal is returned by ServeInspector for addresses that are
+// reachable from other machines.
+var ErrInspectorNotLocal = errors.New("inspector must listen on a loopback address")
+
+// ServeInspector starts an Inspector on addr, e.g. "localhost:6060". Only
+// loopback addresses are accepted, and only requests naming a loopback host,
+// so web pages can not reach it through DNS rebinding.
+func (world *World) ServeInspector(addr string) (*Inspector, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return nil, fmt.Errorf("%w: %s", ErrInspectorNotLocal, addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	inspector := &Inspector{world: world, listener: listener, requests: make(chan func()), closed: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entities", inspector.handle(inspector.entities))
+	mux.HandleFunc("GET /entities/{id}", inspector.handle(inspector.entity))
+	mux.HandleFunc("PUT /entities/{id}/components/{name}", inspector.respond(inspector.replaceComponent))
+	mux.HandleFunc("DELETE /entities/{id}", inspector.handle(inspector.deleteEntity))
+	mux.HandleFunc("GET /groups", inspector.handle(inspector.groups))
+	mux.HandleFunc("GET /systems", inspector.handle(inspector.systems))
+	inspector.server = &http.Server{Handler: inspector.localOnly(mux)}
+	world.inspector = inspector
+
+	go func() {
+		if err := inspector.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			world.Logger.Error("Inspector stopped", "addr", addr, "error", err)
+		}
+	}()
+	world.Logger.Info("Inspector listening", "addr", inspector.Addr())
+	return inspector, nil
+}
+
+// Addr is the address the inspector listens on.
+func (inspector *Inspector) Addr() string {
+	return inspector.listener.Addr().String()
+}
+
+// Close stops the inspector. Requests still waiting for the main loop are
+// answered with 503 Service Unavailable.
+func (inspector *Inspector) Close() error {
+	if inspector.world.inspector == inspector {
+		inspector.world.inspector = nil
+	}
+	inspector.closeOnce.Do(func() { close(inspector.closed) })
+	return inspector.server.Shutdown(context.Background())
+}
+
+// poll answers the requests that are waiting for the main loop.
+func (inspector *Inspector) poll() {
+	for {
+		select {
+		case request := <-inspector.requests:
+			request()
+		default:
+			return
+		}
+	}
+}
+
+type inspectorError struct {
+	status int
+	err    error
+}
+
+func (err *inspectorError) Error() string {
+	return err.err.Error()
+}
+
+var (
+	errInspectorClosed = &inspectorError{http.StatusServiceUnavailable, errors.New("inspector closed")}
+	errRequestCanceled = errors.New("request canceled")
+)
+
+// localOnly rejects requests whose Host is not a loopback name.
+func (inspector *Inspector) localOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if name, _, err := net.SplitHostPort(host); err == nil {
+			host = name
+		}
+		switch strings.Trim(host, "[]") {
+		case "localhost", "127.0.0.1", "::1":
+			next.ServeHTTP(w, r)
+		default:
+			inspector.write(w, r, nil, &inspectorError{http.StatusForbidden, fmt.Errorf("host %q is not local", r.Host)})
+		}
+	})
+}
+
+// handle runs f on the main loop and writes its result as JSON.
+func (inspector *Inspector) handle(f func(r *http.Request) (any, error)) http.HandlerFunc {
+	return inspector.respond(func(r *http.Request) (any, error) {
+		return inspector.onLoop(r, func() (any, error) {
+			return f(r)
+		})
+	})
+}
+
+// respond runs f on the request's goroutine and writes its result as JSON.
+func (inspector *Inspector) respond(f func(r *http.Request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value, err := f(r)
+		if errors.Is(err, errRequestCanceled) {
+			return
+		}
+		inspector.write(w, r, value, err)
+	}
+}
+
+// onLoop runs f on the main loop and waits for its result.
+func (inspector *Inspector) onLoop(r *http.Request, f func() (any, error)) (any, error) {
+	type result struct {
+		value any
+		err   error
+	}
+	done := make(chan result, 1)
+	request := func() {
+		value, err := f()
+		done <- result{value, err}
+	}
+	select {
+	case inspector.requests <- request:
+	case <-inspector.closed:
+		return nil, errInspectorClosed
+	case <-r.Context().Done():
+		return nil, errRequestCanceled
+	}
+
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-inspector.closed:
+		return nil, errInspectorClosed
+	case <-r.Context().Done():
+		return nil, errRequestCanceled
+	}
+}
+
+func (inspector *Inspector) write(w http.ResponseWriter, r *http.Request, value any, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		status := http.StatusInternalServerError
+		var inspectorErr *inspectorError
+		if errors.As(err, &inspectorErr) {
+			status = inspectorErr.status
+		}
+		w.WriteHeader(status)
+		value = map[string]string{"Error": err.Error()}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		inspector.world.Logger.Error("Failed writing inspector response", "path", r.URL.Path, "error", err)
+	}
+}
+
+type inspectedEntity struct {
+	ID         uint32
+	Name       Name           `json:",omitempty"`
+	Components []string       `json:",omitempty"`
+	Values     map[string]any `json:",omitempty"`
+}
+
+func (inspector *Inspector) entities(*http.Request) (any, error) {
+	storage := inspector.world.components
+	entities := []inspectedEntity{}
+	for _, entity := range storage.sortedEntities() {
+		inspected := inspectedEntity{ID: entity, Name: storage.names.names[entity]}
+		for _, t := range inspector.componentTypes(entity) {
+			inspected.Components = append(inspected.Components, componentLabel(t))
+		}
+		entities = append(entities, inspected)
+	}
+	return entities, nil
+}
+
+func (inspector *Inspector) entity(r *http.Request) (any, error) {
+	entity, err := inspector.entityID(r)
+	if err != nil {
+		return nil, err
+	}
+	inspected := inspectedEntity{ID: entity, Name: inspector.world.components.names.names[entity], Values: map[string]any{}}
+	for _, t := range inspector.componentTypes(entity) {
+		component, _ := inspector.world.GetEntityComponent(entity, t)
+		inspected.Values[componentLabel(t)] = component
+	}
+	return inspected, nil
+}
+
+// replaceComponent decodes the request body over the entity's component, so
+// fields left out keep their values.
+func (inspector *Inspector) replaceComponent(r *http.Request) (any, error) {
+	name := r.PathValue("name")
+	var t reflect.Type
+	var existing any
+	_, err := inspector.onLoop(r, func() (any, error) {
+		entity, err := inspector.entityID(r)
+		if err != nil {
+			return nil, err
+		}
+		t, _ = ComponentType(name)
+		for _, candidate := range inspector.componentTypes(entity) {
+			if t == nil && componentLabel(candidate) == name {
+				t = candidate
+			}
+		}
+		if t == nil {
+			return nil, &inspectorError{http.StatusNotFound, fmt.Errorf("unknown component %q", name)}
+		}
+		if component, ok := inspector.world.GetEntityComponent(entity, t); ok {
+			existing = copyComponent(component)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	value := reflect.New(t)
+	if existing != nil {
+		value.Elem().Set(reflect.ValueOf(existing))
+	}
+	if err := json.NewDecoder(r.Body).Decode(value.Interface()); err != nil {
+		return nil, &inspectorError{http.StatusBadRequest, fmt.Errorf("decoding %s: %w", name, err)}
+	}
+
+	return inspector.onLoop(r, func() (any, error) {
+		entity, err := inspector.entityID(r)
+		if err != nil {
+			return nil, err
+		}
+		inspector.world.ReplaceComponent(entity, value.Elem().Interface())
+		return inspector.entity(r)
+	})
+}
+
+func (inspector *Inspector) deleteEntity(r *http.Request) (any, error) {
+	entity, err := inspector.entityID(r)
+	if err != nil {
+		return nil, err
+	}
+	inspector.world.DeleteEntity(entity)
+	return map[string]uint32{"Deleted": entity}, nil
+}
+
+type inspectedGroup struct {
+	Matcher  string
+	Entities int
+}
+
+func (inspector *Inspector) groups(*http.Request) (any, error) {
+	groups := []inspectedGroup{}
+	for matcher, group := range inspector.world.groups {
+		groups = append(groups, inspectedGroup{Matcher: describeMatcher(matcher), Entities: len(group.GetEntities())})
+	}
+	slices.SortFunc(groups, func(a, b inspectedGroup) int {
+		return strings.Compare(a.Matcher, b.Matcher)
+	})
+	return groups, nil
+}
+
+type inspectedSystem struct {
+	SystemStats
+	Enabled bool
+}
+
+func (inspector *Inspector) systems(*http.Request) (any, error) {
+	world := inspector.world
+	stats := world.Stats()
+	systems := []inspectedSystem{}
+	for i, system := range world.statsSystems() {
+		systems = append(systems, inspectedSystem{SystemStats: stats.Systems[i], Enabled: world.SystemEnabled(system)})
+	}
+	return systems, nil
+}
+
+func (inspector *Inspector) entityID(r *http.Request) (uint32, error) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 32)
+	if err != nil {
+		return 0, &inspectorError{http.StatusBadRequest, fmt.Errorf("invalid entity id %q", r.PathValue("id"))}
+	}
+	entity := uint32(id)
+	if !inspector.world.components.entities.Contains(entity) {
+		return 0, &inspectorError{http.StatusNotFound, fmt.Errorf("entity %d does not exist", entity)}
+	}
+	return entity, nil
+}
+
+// componentTypes returns the types of entity's components, sorted by label.
+func (inspector *Inspector) componentTypes(entity uint32) []reflect.Type {
+	var types []reflect.Type
+	storage := inspector.world.components
+	for t, idx := range storage.registry {
+		if _, ok := storage.componentSets[idx].get(entity); ok {
+			types = append(types, t)
+		}
+	}
+	slices.SortFunc(types, func(a, b reflect.Type) int {
+		return strings.Compare(componentLabel(a), componentLabel(b))
+	})
+	return types
+}
+
+// componentLabel is t's registered name, or its Go type.
+func componentLabel(t reflect.Type) string {
+	if name, ok := ComponentName(t); ok {
+		return name
+	}
+	return t.String()
+}
+
+func describeMatcher(matcher Matcher) string {
+	components := func(op string, types []reflect.Type) string {
+		labels := make([]string, len(types))
+		for i, t := range types {
+			labels[i] = componentLabel(t)
+		}
+		return op + "(" + strings.Join(labels, ", ") + ")"
+	}
+	matchers := func(op string, matchers []Matcher) string {
+		descriptions := make([]string, len(matchers))
+		for i, m := range matchers {
+			descriptions[i] = describeMatcher(m)
+		}
+		return op + "(" + strings.Join(descriptions, ", ") + ")"
+	}
+	switch m := matcher.(type) {
+	case *AllOfComponentMatcher:
+		return components("AllOf", m.Components)
+	case *AnyOfComponentMatcher:
+		return components("AnyOf", m.Components)
+	case *NoneOfComponentMatcher:
+		return components("NoneOf", m.Components)
+	case *AllOfMatcher:
+		return matchers("AllOf", m.Matchers)
+	case *AnyOfMatcher:
+		return matchers("AnyOf", m.Matchers)
+	case *NoneOfMatcher:
+		return matchers("NoneOf", m.Matchers)
+	}
+	return fmt.Sprintf("%T", matcher)
+}
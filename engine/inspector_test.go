@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inspect sends a request to the inspector and polls the world like the main
+// loop does until the response arrives.
+func inspect(t *testing.T, inspector *Inspector, method, path, body string) (int, string) {
+	request, err := http.NewRequest(method, "http://"+inspector.Addr()+path, strings.NewReader(body))
+	require.NoError(t, err)
+	type result struct {
+		response *http.Response
+		err      error
+	}
+	done := make(chan result)
+	go func() {
+		response, err := http.DefaultClient.Do(request)
+		done <- result{response, err}
+	}()
+	for {
+		select {
+		case res := <-done:
+			require.NoError(t, res.err)
+			defer res.response.Body.Close()
+			var out json.RawMessage
+			require.NoError(t, json.NewDecoder(res.response.Body).Decode(&out))
+			return res.response.StatusCode, string(out)
+		default:
+			inspector.poll()
+		}
+	}
+}
+
+func TestInspector(t *testing.T) {
+	world := newWorld()
+	_, err := world.ServeInspector("0.0.0.0:0")
+	require.ErrorIs(t, err, ErrInspectorNotLocal)
+
+	inspector, err := world.ServeInspector("127.0.0.1:0")
+	require.NoError(t, err)
+	defer inspector.Close()
+	player := world.CreateEntity(Name("Player"), savedPosition{X: 1, Y: 2})
+	other := world.CreateEntity(savedPosition{})
+	world.GetGroup(savedPositionMatcher)
+
+	status, body := inspect(t, inspector, http.MethodGet, "/entities", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `[{"ID":0,"Name":"Player","Components":["engine.Name","test.Position"]},{"ID":1,"Components":["test.Position"]}]`, body)
+
+	status, body = inspect(t, inspector, http.MethodPut, "/entities/0/components/test.Position", `{"Y":5}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"ID":0,"Name":"Player","Values":{"engine.Name":"Player","test.Position":{"X":1,"Y":5}}}`, body)
+	position, _ := world.GetEntityComponent(player, reflect.TypeOf(savedPosition{}))
+	assert.Equal(t, savedPosition{X: 1, Y: 5}, position)
+
+	status, _ = inspect(t, inspector, http.MethodDelete, "/entities/1", "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.False(t, world.components.entities.Contains(other))
+	status, _ = inspect(t, inspector, http.MethodGet, "/entities/1", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	_, body = inspect(t, inspector, http.MethodGet, "/groups", "")
+	assert.JSONEq(t, `[{"Matcher":"AllOf(test.Position)","Entities":1}]`, body)
+}
+
+func TestInspectorRejectsOtherHosts(t *testing.T) {
+	inspector, err := newWorld().ServeInspector("127.0.0.1:0")
+	require.NoError(t, err)
+	defer inspector.Close()
+
+	request, err := http.NewRequest(http.MethodGet, "http://"+inspector.Addr()+"/entities", nil)
+	require.NoError(t, err)
+	request.Host = "attacker.example:" + strings.Split(inspector.Addr(), ":")[1]
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusForbidden, response.StatusCode)
+}
+
+func TestInspectorCloseAnswersWaitingRequests(t *testing.T) {
+	inspector, err := newWorld().ServeInspector("127.0.0.1:0")
+	require.NoError(t, err)
+
+	responses := make(chan int)
+	go func() {
+		response, err := http.Get("http://" + inspector.Addr() + "/entities")
+		if !assert.NoError(t, err) {
+			responses <- 0
+			return
+		}
+		response.Body.Close()
+		responses <- response.StatusCode
+	}()
+	// Take the request off the main loop's queue without running it, as a
+	// loop that stopped would.
+	<-inspector.requests
+	assert.NoError(t, inspector.Close())
+	assert.Equal(t, http.StatusServiceUnavailable, <-responses)
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LogConfig configures the World's logger.
+type LogConfig struct {
+	// Level is the lowest level logged, slog.LevelInfo if nil.
+	Level slog.Leveler
+	// Output is where records are written as text, os.Stderr if nil.
+	Output io.Writer
+	// Handler, if set, is used instead of a text handler on Output.
+	Handler slog.Handler
+	// Repeat is how long the same message from the same system is held back
+	// after being logged. 0 logs everything.
+	Repeat time.Duration
+}
+
+// ConfigureLogging replaces the World's logger and makes it slog's default.
+// Records get the current frame and, while a system runs, the system.
+func (world *World) ConfigureLogging(config LogConfig) {
+	handler := config.Handler
+	if handler == nil {
+		output := config.Output
+		if output == nil {
+			output = os.Stderr
+		}
+		level := config.Level
+		if level == nil {
+			level = slog.LevelInfo
+		}
+		handler = slog.NewTextHandler(output, &slog.HandlerOptions{Level: level})
+	}
+	world.Logger = world.newLogger(handler, config.Repeat)
+	slog.SetDefault(world.Logger)
+}
+
+// EntityLogger returns the World's logger with the entity, and its name if
+// it has one, added to every record.
+func (world *World) EntityLogger(entity uint32) *slog.Logger {
+	return world.Logger.With("entity", world.components.names.ref(entity))
+}
+
+// logContext is what the world is doing, for its log handler.
+type logContext struct {
+	frame  atomic.Uint64
+	system atomic.Pointer[string]
+}
+
+func (world *World) newLogger(handler slog.Handler, repeat time.Duration) *slog.Logger {
+	return slog.New(&contextHandler{
+		handler: handler,
+		context: world.logContext,
+		limiter: &repeatLimiter{repeat: repeat, seen: map[repeatKey]*repeated{}},
+	})
+}
+
+// running records that system is running until the returned func is called.
+func (state *logContext) running(system System) func() {
+	name := reflect.TypeOf(system).String()
+	state.system.Store(&name)
+	return func() {
+		state.system.Store(nil)
+	}
+}
+
+type contextHandler struct {
+	handler slog.Handler
+	context *logContext
+	limiter *repeatLimiter
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	system := ""
+	if name := h.context.system.Load(); name != nil {
+		system = *name
+	}
+	held, ok := h.limiter.allow(repeatKey{level: record.Level, message: record.Message, system: system}, record.Time)
+	if !ok {
+		return nil
+	}
+	record = record.Clone()
+	record.AddAttrs(slog.Uint64("frame", h.context.frame.Load()))
+	if system != "" {
+		record.AddAttrs(slog.String("system", system))
+	}
+	if held > 0 {
+		record.AddAttrs(slog.Int("repeated", held))
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{handler: h.handler.WithAttrs(attrs), context: h.context, limiter: h.limiter}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{handler: h.handler.WithGroup(name), context: h.context, limiter: h.limiter}
+}
+
+type repeatKey struct {
+	level   slog.Level
+	message string
+	system  string
+}
+
+type repeated struct {
+	logged time.Time
+	held   int
+}
+
+// repeatLimiter holds back records that repeat within repeat of the last one
+// logged.
+type repeatLimiter struct {
+	mu     sync.Mutex
+	repeat time.Duration
+	seen   map[repeatKey]*repeated
+}
+
+// allow reports whether a record with key logged at now is written, and how
+// many were held back before it.
+func (limiter *repeatLimiter) allow(key repeatKey, now time.Time) (int, bool) {
+	if limiter.repeat <= 0 {
+		return 0, true
+	}
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	last, ok := limiter.seen[key]
+	if !ok {
+		limiter.seen[key] = &repeated{logged: now}
+		return 0, true
+	}
+	if now.Sub(last.logged) < limiter.repeat {
+		last.held++
+		return 0, false
+	}
+	held := last.held
+	*last = repeated{logged: now}
+	return held, true
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogging(t *testing.T) {
+	defer slog.SetDefault(slog.Default())
+	var out bytes.Buffer
+	world := newWorld()
+	world.ConfigureLogging(LogConfig{Handler: slog.NewJSONHandler(&out, nil), Repeat: time.Hour})
+	world.AddSystems(&failingSystem{})
+	for range 3 {
+		world.update()
+	}
+	player := world.CreateEntity(Name("Player"))
+	world.EntityLogger(player).Warn("Looking odd")
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2, "the failure in frames 1 and 2 is held back")
+	var failed, odd map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &failed))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &odd))
+	assert.Equal(t, "System failed", failed["msg"])
+	assert.Equal(t, "*engine.failingSystem", failed["system"])
+	assert.Equal(t, float64(0), failed["frame"])
+	assert.Equal(t, "broken", failed["error"])
+	assert.Equal(t, "0 (Player)", odd["entity"])
+	assert.Equal(t, float64(3), odd["frame"])
+	assert.NotContains(t, odd, "system")
+}
+
+func TestRepeatLimiter(t *testing.T) {
+	limiter := &repeatLimiter{repeat: time.Second, seen: map[repeatKey]*repeated{}}
+	key := repeatKey{level: slog.LevelError, message: "System failed"}
+	start := time.Now()
+	for i, want := range []bool{true, false, false, true} {
+		held, ok := limiter.allow(key, start.Add(time.Duration(i)*400*time.Millisecond))
+		assert.Equal(t, want, ok, "record %d", i)
+		if i == 3 {
+			assert.Equal(t, 2, held)
+		}
+	}
+	_, ok := limiter.allow(repeatKey{level: slog.LevelError, message: "Other"}, start)
+	assert.True(t, ok)
+}
@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+)
+
+func init() {
+	RegisterComponent[Name]("engine.Name")
+}
+
+// Name names an entity in FindByName, logs and errors. Names need not be
+// unique.
+type Name string
+
+var nameType = reflect.TypeFor[Name]()
+
+// FindByName returns the first entity given name that still has it.
+func (world *World) FindByName(name string) (uint32, bool) {
+	entities := world.components.names.entities[Name(name)]
+	if len(entities) == 0 {
+		return 0, false
+	}
+	return entities[0], true
+}
+
+// nameIndex maps names to entities and back, kept up to date by the Name
+// component set.
+type nameIndex struct {
+	entities map[Name][]uint32
+	names    map[uint32]Name
+}
+
+func newNameIndex() *nameIndex {
+	return &nameIndex{entities: map[Name][]uint32{}, names: map[uint32]Name{}}
+}
+
+func (index *nameIndex) add(entity uint32, name Name) {
+	index.names[entity] = name
+	index.entities[name] = append(index.entities[name], entity)
+}
+
+func (index *nameIndex) remove(entity uint32) {
+	name, ok := index.names[entity]
+	if !ok {
+		return
+	}
+	delete(index.names, entity)
+	entities := slices.DeleteFunc(index.entities[name], func(e uint32) bool { return e == entity })
+	if len(entities) == 0 {
+		delete(index.entities, name)
+		return
+	}
+	index.entities[name] = entities
+}
+
+func (index *nameIndex) clone() *nameIndex {
+	clone := &nameIndex{
+		entities: make(map[Name][]uint32, len(index.entities)),
+		names:    make(map[uint32]Name, len(index.names)),
+	}
+	for name, entities := range index.entities {
+		clone.entities[name] = slices.Clone(entities)
+	}
+	for entity, name := range index.names {
+		clone.names[entity] = name
+	}
+	return clone
+}
+
+func (index *nameIndex) ref(entity uint32) entityRef {
+	return entityRef{id: entity, name: index.names[entity]}
+}
+
+// entityRef is how logs and errors refer to an entity.
+type entityRef struct {
+	id   uint32
+	name Name
+}
+
+func (ref entityRef) String() string {
+	if ref.name == "" {
+		return fmt.Sprint(ref.id)
+	}
+	return fmt.Sprintf("%d (%s)", ref.id, ref.name)
+}
+
+func (ref entityRef) LogValue() slog.Value {
+	return slog.StringValue(ref.String())
+}
+
+// snapshotRef refers to an entity in a snapshot, finding its name among its
+// components.
+func snapshotRef(entity EntitySnapshot) entityRef {
+	ref := entityRef{id: entity.ID}
+	for _, component := range entity.Components {
+		if name, ok := component.(Name); ok {
+			ref.name = name
+		}
+	}
+	return ref
+}
@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"bytes"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindByName(t *testing.T) {
+	world := newWorld()
+	player := world.CreateEntity(Name("player"), savedPosition{})
+	door := world.CreateEntity(Name("door"))
+	world.CreateEntity(Name("door"))
+
+	found, ok := world.FindByName("player")
+	assert.True(t, ok)
+	assert.Equal(t, player, found)
+	found, _ = world.FindByName("door")
+	assert.Equal(t, door, found)
+
+	history := world.History()
+	history.BeginTurn()
+	world.ReplaceComponent(player, Name("hero"))
+	_, ok = world.FindByName("player")
+	assert.False(t, ok)
+	world.DeleteEntity(player)
+	_, ok = world.FindByName("hero")
+	assert.False(t, ok)
+
+	assert.True(t, history.Undo())
+	found, ok = world.FindByName("player")
+	assert.True(t, ok, "undo restores the index")
+	assert.Equal(t, player, found)
+
+	snapshot, err := world.Snapshot()
+	require.NoError(t, err)
+	clone := world.Clone()
+	world.RemoveComponent(player, reflect.TypeOf(Name("")))
+	_, ok = world.FindByName("player")
+	assert.False(t, ok)
+	_, ok = clone.FindByName("player")
+	assert.True(t, ok, "clones have their own index")
+	require.NoError(t, world.Restore(snapshot))
+	_, ok = world.FindByName("player")
+	assert.True(t, ok)
+}
+
+func TestTags(t *testing.T) {
+	world := newWorld()
+	entity := world.CreateEntity(savedTag{})
+	assert.True(t, world.HasComponent(entity, reflect.TypeOf(savedTag{})))
+	set := world.components.getComponentSet(reflect.TypeOf(savedTag{}))
+	assert.Nil(t, set.components, "tags store no value")
+	assert.Equal(t, savedTag{}, world.GetUniqueComponent(reflect.TypeOf(savedTag{})))
+
+	tag, ok := world.GetEntityComponent(entity, reflect.TypeOf(savedTag{}))
+	assert.True(t, ok)
+	assert.Equal(t, savedTag{}, tag)
+
+	history := world.History()
+	history.BeginTurn()
+	world.DeleteEntity(entity)
+	assert.True(t, history.Undo())
+	assert.True(t, world.HasComponent(entity, reflect.TypeOf(savedTag{})))
+}
+
+func TestLogsShowNames(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	world := newWorld()
+	entity := world.CreateEntity(Name("player"), savedPosition{})
+	world.AddComponent(entity, savedPosition{})
+	assert.Contains(t, logs.String(), `entity="0 (player)"`)
+}
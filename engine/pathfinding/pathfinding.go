@@ -0,0 +1,257 @@
+// Package pathfinding searches the cells of a tilemap: shortest paths with
+// A*, the cells reachable from a start and distance fields for steering many
+// entities toward the same goals.
+package pathfinding
+
+import (
+	"container/heap"
+	"math"
+	"slices"
+
+	"github.com/lakrsv/parkour-engine/engine"
+)
+
+// CostFunc returns the cost of stepping from a cell to a neighboring one.
+type CostFunc func(from, to engine.Cell) float64
+
+// Step costs 1 for a straight step and the square root of 2 for a diagonal
+// one.
+func Step(from, to engine.Cell) float64 {
+	if from.X != to.X && from.Y != to.Y {
+		return math.Sqrt2
+	}
+	return 1
+}
+
+// Uniform costs 1 for every step.
+func Uniform(from, to engine.Cell) float64 {
+	return 1
+}
+
+// Grid is the area a search runs on.
+type Grid struct {
+	Width, Height int
+	// Walkable reports whether a cell can be entered. Every cell is if nil.
+	Walkable func(cell engine.Cell) bool
+	// Cost is the cost of a step, Step if nil. With costs below 1 Path may
+	// miss the cheapest path.
+	Cost CostFunc
+	// Diagonal allows diagonal steps, but not past the corner of a cell that
+	// can not be entered.
+	Diagonal bool
+}
+
+// ForEntity returns a Grid over the world's Tilemap where the walkable cells
+// are the ones World.CanOccupy lets entity move into.
+func ForEntity(world *engine.World, entity uint32) (Grid, error) {
+	tilemap, err := engine.Resource[engine.Tilemap](world)
+	if err != nil {
+		return Grid{}, err
+	}
+	return Grid{
+		Width:  tilemap.Width,
+		Height: tilemap.Height,
+		Walkable: func(cell engine.Cell) bool {
+			return world.CanOccupy(entity, cell) == nil
+		},
+	}, nil
+}
+
+func (grid Grid) inBounds(cell engine.Cell) bool {
+	return cell.X >= 0 && cell.Y >= 0 && cell.X < grid.Width && cell.Y < grid.Height
+}
+
+func (grid Grid) walkable(cell engine.Cell) bool {
+	return grid.inBounds(cell) && (grid.Walkable == nil || grid.Walkable(cell))
+}
+
+func (grid Grid) cost(from, to engine.Cell) float64 {
+	if grid.Cost == nil {
+		return Step(from, to)
+	}
+	return grid.Cost(from, to)
+}
+
+var (
+	straight = []engine.Cell{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+	diagonal = []engine.Cell{{X: 1, Y: -1}, {X: 1, Y: 1}, {X: -1, Y: 1}, {X: -1, Y: -1}}
+)
+
+// neighbors returns the walkable cells one step from cell, in the order of
+// Tilemap.Neighbors.
+func (grid Grid) neighbors(cell engine.Cell) []engine.Cell {
+	neighbors := make([]engine.Cell, 0, 8)
+	for _, offset := range straight {
+		if next := cell.Add(offset); grid.walkable(next) {
+			neighbors = append(neighbors, next)
+		}
+	}
+	if !grid.Diagonal {
+		return neighbors
+	}
+	for _, offset := range diagonal {
+		next := cell.Add(offset)
+		if grid.walkable(next) && grid.walkable(cell.Add(engine.Cell{X: offset.X})) && grid.walkable(cell.Add(engine.Cell{Y: offset.Y})) {
+			neighbors = append(neighbors, next)
+		}
+	}
+	return neighbors
+}
+
+// estimate is the cost of the shortest way from one cell to another with
+// nothing in the way, counting steps at their lowest cost: Step's, or 1 for
+// every step with any other Cost.
+func (grid Grid) estimate(from, to engine.Cell) float64 {
+	dx, dy := math.Abs(float64(from.X-to.X)), math.Abs(float64(from.Y-to.Y))
+	switch {
+	case !grid.Diagonal:
+		return dx + dy
+	case grid.Cost != nil:
+		return math.Max(dx, dy)
+	}
+	return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+}
+
+// Path returns the cheapest path from one cell to another with A*, both
+// included, and its cost. It returns false if to can not be reached.
+func (grid Grid) Path(from, to engine.Cell) ([]engine.Cell, float64, bool) {
+	if !grid.inBounds(from) || !grid.walkable(to) && from != to {
+		return nil, 0, false
+	}
+	costs := map[engine.Cell]float64{from: 0}
+	previous := map[engine.Cell]engine.Cell{}
+	open := &queue{{cell: from, priority: grid.estimate(from, to)}}
+	for open.Len() > 0 {
+		current := heap.Pop(open).(item)
+		if current.cell == to {
+			path := []engine.Cell{to}
+			for cell := to; cell != from; {
+				cell = previous[cell]
+				path = append(path, cell)
+			}
+			slices.Reverse(path)
+			return path, costs[to], true
+		}
+		if current.cost > costs[current.cell] {
+			// A cheaper way here was found after this one was queued.
+			continue
+		}
+		for _, next := range grid.neighbors(current.cell) {
+			cost := current.cost + grid.cost(current.cell, next)
+			if known, ok := costs[next]; ok && known <= cost {
+				continue
+			}
+			costs[next] = cost
+			previous[next] = current.cell
+			heap.Push(open, item{cell: next, cost: cost, priority: cost + grid.estimate(next, to)})
+		}
+	}
+	return nil, 0, false
+}
+
+// Reachable returns the cells that can be reached from start, start
+// included, in the order a breadth-first search finds them.
+func (grid Grid) Reachable(start engine.Cell) []engine.Cell {
+	if !grid.inBounds(start) {
+		return nil
+	}
+	seen := map[engine.Cell]bool{start: true}
+	cells := []engine.Cell{start}
+	for i := 0; i < len(cells); i++ {
+		for _, next := range grid.neighbors(cells[i]) {
+			if !seen[next] {
+				seen[next] = true
+				cells = append(cells, next)
+			}
+		}
+	}
+	return cells
+}
+
+// Field holds the cost of the cheapest way from every cell to the nearest of
+// the goals it was made for.
+type Field struct {
+	grid  Grid
+	costs []float64
+}
+
+// DistanceField returns the Field of the cheapest way from every cell to the
+// nearest goal. An entity that keeps taking Field.Next reaches a goal.
+func (grid Grid) DistanceField(goals ...engine.Cell) *Field {
+	field := &Field{grid: grid, costs: make([]float64, grid.Width*grid.Height)}
+	for i := range field.costs {
+		field.costs[i] = math.Inf(1)
+	}
+	open := &queue{}
+	for _, goal := range goals {
+		if grid.inBounds(goal) {
+			field.costs[field.index(goal)] = 0
+			heap.Push(open, item{cell: goal})
+		}
+	}
+	for open.Len() > 0 {
+		current := heap.Pop(open).(item)
+		if current.cost > field.costs[field.index(current.cell)] {
+			continue
+		}
+		for _, next := range grid.neighbors(current.cell) {
+			// The field is walked toward the goals, so a step costs what
+			// stepping from next to current does.
+			cost := current.cost + grid.cost(next, current.cell)
+			if i := field.index(next); cost < field.costs[i] {
+				field.costs[i] = cost
+				heap.Push(open, item{cell: next, cost: cost, priority: cost})
+			}
+		}
+	}
+	return field
+}
+
+func (field *Field) index(cell engine.Cell) int {
+	return cell.Y*field.grid.Width + cell.X
+}
+
+// At returns the cost from cell to the nearest goal. It returns false if no
+// goal can be reached from cell.
+func (field *Field) At(cell engine.Cell) (float64, bool) {
+	if !field.grid.inBounds(cell) {
+		return 0, false
+	}
+	cost := field.costs[field.index(cell)]
+	return cost, !math.IsInf(cost, 1)
+}
+
+// Next returns the step from cell toward the nearest goal. It returns false
+// at a goal and where no goal can be reached.
+func (field *Field) Next(cell engine.Cell) (engine.Cell, bool) {
+	if cost, ok := field.At(cell); !ok || cost == 0 {
+		return cell, false
+	}
+	next, best := cell, math.Inf(1)
+	for _, neighbor := range field.grid.neighbors(cell) {
+		if cost, ok := field.At(neighbor); ok && cost+field.grid.cost(cell, neighbor) < best {
+			next, best = neighbor, cost+field.grid.cost(cell, neighbor)
+		}
+	}
+	return next, next != cell
+}
+
+type item struct {
+	cell     engine.Cell
+	cost     float64
+	priority float64
+}
+
+// queue is a heap of cells, cheapest priority first.
+type queue []item
+
+func (q queue) Len() int           { return len(q) }
+func (q queue) Less(i, j int) bool { return q[i].priority < q[j].priority }
+func (q queue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *queue) Push(x any)        { *q = append(*q, x.(item)) }
+func (q *queue) Pop() any {
+	old := *q
+	last := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return last
+}
@@ -0,0 +1,150 @@
+package pathfinding
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/lakrsv/parkour-engine/engine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gridOf builds a Grid from rows of text where # can not be entered.
+func gridOf(rows ...string) Grid {
+	return Grid{
+		Width:  len(rows[0]),
+		Height: len(rows),
+		Walkable: func(cell engine.Cell) bool {
+			return rows[cell.Y][cell.X] != '#'
+		},
+	}
+}
+
+var maze = gridOf(
+	"..#....",
+	"..#.##.",
+	"..#..#.",
+	"....#..",
+)
+
+func TestPath(t *testing.T) {
+	path, cost, ok := maze.Path(engine.Cell{X: 0, Y: 0}, engine.Cell{X: 3, Y: 0})
+	require.True(t, ok)
+	assert.Equal(t, 9.0, cost)
+	assert.Len(t, path, 10)
+	assert.Equal(t, engine.Cell{X: 0, Y: 0}, path[0])
+	assert.Equal(t, engine.Cell{X: 3, Y: 0}, path[len(path)-1])
+	for i := 1; i < len(path); i++ {
+		step := engine.Cell{X: path[i].X - path[i-1].X, Y: path[i].Y - path[i-1].Y}
+		assert.Equal(t, 1, abs(step.X)+abs(step.Y), "steps are straight")
+	}
+
+	_, _, ok = maze.Path(engine.Cell{X: 0, Y: 0}, engine.Cell{X: 2, Y: 0})
+	assert.False(t, ok, "walls can not be reached")
+	path, cost, ok = maze.Path(engine.Cell{X: 1, Y: 1}, engine.Cell{X: 1, Y: 1})
+	assert.True(t, ok)
+	assert.Zero(t, cost)
+	assert.Equal(t, []engine.Cell{{X: 1, Y: 1}}, path)
+	_, _, ok = maze.Path(engine.Cell{X: 0, Y: 0}, engine.Cell{X: 9, Y: 0})
+	assert.False(t, ok)
+}
+
+func TestDiagonalPath(t *testing.T) {
+	grid := maze
+	grid.Diagonal = true
+	path, cost, ok := grid.Path(engine.Cell{X: 0, Y: 0}, engine.Cell{X: 3, Y: 0})
+	require.True(t, ok)
+	assert.Len(t, path, 9)
+	assert.InDelta(t, 7+math.Sqrt2, cost, 1e-9)
+	assert.Equal(t, engine.Cell{X: 1, Y: 1}, path[1])
+	assert.Contains(t, path, engine.Cell{X: 3, Y: 3}, "no cutting the corner of (2, 2)")
+
+	grid.Cost = Uniform
+	_, cost, ok = grid.Path(engine.Cell{X: 0, Y: 0}, engine.Cell{X: 1, Y: 1})
+	assert.True(t, ok)
+	assert.Equal(t, 1.0, cost)
+
+	// With diagonal steps as cheap as straight ones, an estimate counting them
+	// at Step's cost is too high here and finds a path of 7.
+	grid = gridOf(
+		"#..##",
+		".....",
+		"#....",
+		".....",
+		"..##.",
+		"#....",
+		"####.",
+	)
+	grid.Diagonal = true
+	grid.Cost = Uniform
+	_, cost, ok = grid.Path(engine.Cell{X: 3, Y: 5}, engine.Cell{X: 1, Y: 0})
+	assert.True(t, ok)
+	assert.Equal(t, 6.0, cost)
+}
+
+func TestCostFunc(t *testing.T) {
+	grid := gridOf(
+		"...",
+		"...",
+		"...",
+	)
+	// Stepping into the middle row costs a lot, except at its right end.
+	grid.Cost = func(from, to engine.Cell) float64 {
+		if to.Y == 1 && to.X != 2 {
+			return 10
+		}
+		return 1
+	}
+	path, cost, ok := grid.Path(engine.Cell{X: 0, Y: 0}, engine.Cell{X: 0, Y: 2})
+	require.True(t, ok)
+	assert.Equal(t, 6.0, cost)
+	assert.Contains(t, path, engine.Cell{X: 2, Y: 1})
+}
+
+func TestReachable(t *testing.T) {
+	cells := maze.Reachable(engine.Cell{X: 0, Y: 0})
+	assert.Equal(t, engine.Cell{X: 0, Y: 0}, cells[0])
+	assert.Len(t, cells, 21)
+	assert.NotContains(t, cells, engine.Cell{X: 2, Y: 0})
+
+	walled := gridOf(
+		".#.",
+		"##.",
+	)
+	assert.Equal(t, []engine.Cell{{X: 0, Y: 0}}, walled.Reachable(engine.Cell{X: 0, Y: 0}))
+	assert.Nil(t, walled.Reachable(engine.Cell{X: -1, Y: 0}))
+}
+
+func TestDistanceField(t *testing.T) {
+	field := maze.DistanceField(engine.Cell{X: 3, Y: 0}, engine.Cell{X: 0, Y: 3})
+	cost, ok := field.At(engine.Cell{X: 0, Y: 0})
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, cost)
+	cost, _ = field.At(engine.Cell{X: 6, Y: 3})
+	assert.Equal(t, 6.0, cost, "to the nearer goal")
+	_, ok = field.At(engine.Cell{X: 2, Y: 0})
+	assert.False(t, ok)
+
+	var walked []string
+	for cell, ok := (engine.Cell{X: 6, Y: 3}), true; ok; cell, ok = field.Next(cell) {
+		walked = append(walked, string(rune('0'+cell.X))+string(rune('0'+cell.Y)))
+	}
+	assert.Equal(t, "63 62 61 60 50 40 30", strings.Join(walked, " "))
+}
+
+func TestForEntity(t *testing.T) {
+	world := engine.GetInstance()
+	engine.InsertResource(world, engine.NewTilemap(3, 1, "actors"))
+	engine.TrackTiles(world, func(cell engine.Cell) engine.Cell { return cell }, func(_, cell engine.Cell) engine.Cell { return cell })
+	player := world.CreateEntity(engine.Cell{X: 0}, engine.TileLayer("actors"))
+	world.CreateEntity(engine.Cell{X: 2}, engine.TileLayer("actors"))
+
+	grid, err := ForEntity(world, player)
+	require.NoError(t, err)
+	assert.Equal(t, []engine.Cell{{X: 0}, {X: 1}}, grid.Reachable(engine.Cell{X: 0}))
+}
+
+func abs(x int) int {
+	return max(x, -x)
+}
@@ -0,0 +1,269 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrPrefabNotFound = errors.New("prefab not found")
+
+// Prefab describes an entity to spawn. Its components replace those of the
+// same type of the prefab it Extends.
+type Prefab struct {
+	Name       string
+	Extends    string
+	Components []any
+}
+
+// Overrides change the components of a spawned prefab, keyed by registered
+// component name. A value of another type than the component's, e.g.
+// map[string]any, sets fields on top of it.
+type Overrides map[string]any
+
+// Prefabs holds the prefabs a world can spawn.
+type Prefabs struct {
+	mu      sync.RWMutex
+	prefabs map[string]*prefab
+}
+
+type prefab struct {
+	extends    string
+	components []prefabComponent
+}
+
+// prefabComponent is either a whole component value or fields, as JSON, to
+// apply on top of the inherited component of type t.
+type prefabComponent struct {
+	t      reflect.Type
+	value  any
+	fields []byte
+}
+
+func NewPrefabs() *Prefabs {
+	return &Prefabs{prefabs: map[string]*prefab{}}
+}
+
+// Names returns the names of the registered prefabs, sorted.
+func (prefabs *Prefabs) Names() []string {
+	prefabs.mu.RLock()
+	defer prefabs.mu.RUnlock()
+	return slices.Sorted(maps.Keys(prefabs.prefabs))
+}
+
+// Register adds prefab, replacing any prefab of the same name.
+func (prefabs *Prefabs) Register(p Prefab) {
+	entry := &prefab{extends: p.Extends}
+	for _, component := range p.Components {
+		entry.components = append(entry.components, prefabComponent{t: reflect.TypeOf(component), value: component})
+	}
+	prefabs.mu.Lock()
+	defer prefabs.mu.Unlock()
+	prefabs.prefabs[p.Name] = entry
+}
+
+// prefabFile is the layout of prefab data files, keyed by prefab name:
+//
+//	ClosedDoor:
+//	  extends: Door
+//	  components:
+//	    Obstacle: {}
+//	    Render: {Character: "|"}
+//
+// Fields that are not listed keep their inherited value. JSON works too.
+type prefabFile map[string]struct {
+	Extends    string         `yaml:"extends"`
+	Components map[string]any `yaml:"components"`
+}
+
+// Load registers the prefabs in a YAML or JSON data file.
+func (prefabs *Prefabs) Load(data []byte) error {
+	var file prefabFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("loading prefabs: %w", err)
+	}
+	loaded := make(map[string]*prefab, len(file))
+	for name, definition := range file {
+		entry := &prefab{extends: definition.Extends}
+		for _, componentName := range slices.Sorted(maps.Keys(definition.Components)) {
+			component, err := fieldsComponent(componentName, definition.Components[componentName])
+			if err != nil {
+				return fmt.Errorf("loading prefab %q: %w", name, err)
+			}
+			entry.components = append(entry.components, component)
+		}
+		loaded[name] = entry
+	}
+	prefabs.mu.Lock()
+	defer prefabs.mu.Unlock()
+	maps.Copy(prefabs.prefabs, loaded)
+	return nil
+}
+
+// LoadPrefabs registers the prefabs in the data file at path in the world's
+// assets.
+func (world *World) LoadPrefabs(path string) error {
+	data, err := world.Assets.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return world.Prefabs.Load(data)
+}
+
+// Spawn creates an entity from the named prefab with overrides applied.
+func (world *World) Spawn(name string, overrides Overrides) (uint32, error) {
+	components, err := world.Prefabs.Components(name, overrides)
+	if err != nil {
+		return 0, err
+	}
+	return world.CreateEntity(components...), nil
+}
+
+// Components returns copies of the components of the named prefab with
+// overrides applied.
+func (prefabs *Prefabs) Components(name string, overrides Overrides) ([]any, error) {
+	prefabs.mu.RLock()
+	defer prefabs.mu.RUnlock()
+	components, err := prefabs.resolve(name, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, componentName := range slices.Sorted(maps.Keys(overrides)) {
+		component, err := overrideComponent(componentName, overrides[componentName])
+		if err != nil {
+			return nil, fmt.Errorf("spawning prefab %q: %w", name, err)
+		}
+		if components, err = component.apply(components); err != nil {
+			return nil, fmt.Errorf("spawning prefab %q: %w", name, err)
+		}
+	}
+	return components, nil
+}
+
+func (prefabs *Prefabs) resolve(name string, seen []string) ([]any, error) {
+	if slices.Contains(seen, name) {
+		return nil, fmt.Errorf("prefab %q extends itself", name)
+	}
+	entry, ok := prefabs.prefabs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrPrefabNotFound, name)
+	}
+	var components []any
+	if entry.extends != "" {
+		var err error
+		if components, err = prefabs.resolve(entry.extends, append(seen, name)); err != nil {
+			return nil, err
+		}
+	}
+	for _, component := range entry.components {
+		var err error
+		if components, err = component.apply(components); err != nil {
+			return nil, fmt.Errorf("prefab %q: %w", name, err)
+		}
+	}
+	return components, nil
+}
+
+// apply replaces or patches the component of the same type in components.
+func (component prefabComponent) apply(components []any) ([]any, error) {
+	idx := slices.IndexFunc(components, func(c any) bool { return reflect.TypeOf(c) == component.t })
+	var value any
+	if component.value != nil {
+		value = copyComponent(component.value)
+	} else {
+		target := reflect.New(component.t)
+		if idx >= 0 {
+			target.Elem().Set(reflect.ValueOf(components[idx]))
+		}
+		if err := json.Unmarshal(component.fields, target.Interface()); err != nil {
+			return nil, fmt.Errorf("setting fields of %s: %w", component.t, err)
+		}
+		value = target.Elem().Interface()
+	}
+	if idx < 0 {
+		return append(components, value), nil
+	}
+	components[idx] = value
+	return components, nil
+}
+
+func overrideComponent(name string, override any) (prefabComponent, error) {
+	t, ok := ComponentType(name)
+	if !ok {
+		return prefabComponent{}, fmt.Errorf("unknown component %q", name)
+	}
+	if reflect.TypeOf(override) == t {
+		return prefabComponent{t: t, value: override}, nil
+	}
+	return fieldsComponent(name, override)
+}
+
+func fieldsComponent(name string, fields any) (prefabComponent, error) {
+	t, ok := ComponentType(name)
+	if !ok {
+		return prefabComponent{}, fmt.Errorf("unknown component %q", name)
+	}
+	if fields == nil {
+		fields = map[string]any{}
+	}
+	data, err := json.Marshal(runesFromStrings(fields, t))
+	if err != nil {
+		return prefabComponent{}, fmt.Errorf("fields of %s: %w", name, err)
+	}
+	return prefabComponent{t: t, fields: data}, nil
+}
+
+// runesFromStrings turns one character strings into runes wherever t has a
+// rune, so data files can write Character: "#" instead of 35.
+func runesFromStrings(value any, t reflect.Type) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch v := value.(type) {
+	case string:
+		if t.Kind() == reflect.Int32 && utf8.RuneCountInString(v) == 1 {
+			r, _ := utf8.DecodeRuneInString(v)
+			return r
+		}
+	case map[string]any:
+		if t.Kind() != reflect.Struct && t.Kind() != reflect.Map {
+			return value
+		}
+		converted := make(map[string]any, len(v))
+		for key, field := range v {
+			converted[key] = field
+			if t.Kind() == reflect.Map {
+				converted[key] = runesFromStrings(field, t.Elem())
+			} else if f, ok := fieldByName(t, key); ok {
+				converted[key] = runesFromStrings(field, f.Type)
+			}
+		}
+		return converted
+	case []any:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return value
+		}
+		converted := make([]any, len(v))
+		for i, element := range v {
+			converted[i] = runesFromStrings(element, t.Elem())
+		}
+		return converted
+	}
+	return value
+}
+
+// fieldByName finds a field the way encoding/json does, ignoring case.
+func fieldByName(t reflect.Type, name string) (reflect.StructField, bool) {
+	if f, ok := t.FieldByName(name); ok {
+		return f, true
+	}
+	return t.FieldByNameFunc(func(field string) bool { return strings.EqualFold(field, name) })
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type savedGlyph struct {
+	Character rune
+	Tint      struct{ R, G, B uint8 }
+}
+
+func init() {
+	RegisterComponent[savedGlyph]("test.Glyph")
+}
+
+const testPrefabs = `
+Door:
+  components:
+    test.Position: {}
+    test.Glyph: {Character: "|", Tint: {R: 255, G: 255, B: 255}}
+ClosedDoor:
+  extends: Door
+  components:
+    test.Tag:
+    test.Glyph: {Tint: {G: 0}}
+`
+
+func TestSpawnPrefab(t *testing.T) {
+	world := newWorld()
+	world.Prefabs.Register(Prefab{Name: "Chest", Components: []any{savedPosition{}, savedInventory{Items: []string{"key"}}}})
+	require.NoError(t, world.Prefabs.Load([]byte(testPrefabs)))
+
+	door, err := world.Spawn("ClosedDoor", Overrides{"test.Position": savedPosition{X: 3, Y: 4}})
+	require.NoError(t, err)
+	position, _ := positionOf(world, door)
+	assert.Equal(t, savedPosition{X: 3, Y: 4}, position)
+	assert.True(t, world.HasComponent(door, reflect.TypeOf(savedTag{})))
+	glyph, _ := world.GetEntityComponent(door, reflect.TypeOf(savedGlyph{}))
+	assert.Equal(t, '|', glyph.(savedGlyph).Character, "inherited fields are kept")
+	assert.Equal(t, struct{ R, G, B uint8 }{R: 255, B: 255}, glyph.(savedGlyph).Tint)
+
+	first, err := world.Spawn("Chest", Overrides{"test.Position": map[string]any{"Y": 2}})
+	require.NoError(t, err)
+	second, err := world.Spawn("Chest", nil)
+	require.NoError(t, err)
+	position, _ = positionOf(world, first)
+	assert.Equal(t, savedPosition{Y: 2}, position)
+	inventory, _ := world.GetEntityComponent(first, reflect.TypeOf(savedInventory{}))
+	inventory.(savedInventory).Items[0] = "map"
+	inventory, _ = world.GetEntityComponent(second, reflect.TypeOf(savedInventory{}))
+	assert.Equal(t, []string{"key"}, inventory.(savedInventory).Items, "spawned entities share nothing")
+}
+
+func TestPrefabErrors(t *testing.T) {
+	world := newWorld()
+	_, err := world.Spawn("Missing", nil)
+	assert.ErrorIs(t, err, ErrPrefabNotFound)
+
+	world.Prefabs.Register(Prefab{Name: "A", Extends: "B"})
+	world.Prefabs.Register(Prefab{Name: "B", Extends: "A"})
+	_, err = world.Spawn("A", nil)
+	assert.ErrorContains(t, err, "extends itself")
+
+	assert.ErrorContains(t, world.Prefabs.Load([]byte("C:\n  components:\n    Unknown: {}\n")), `unknown component "Unknown"`)
+	world.Prefabs.Register(Prefab{Name: "C", Components: []any{savedPosition{}}})
+	_, err = world.Spawn("C", Overrides{"test.Position": map[string]any{"X": "left"}})
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package engine
 import (
 	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 type Matcher interface {
@@ -39,9 +40,9 @@ func (m *AllOfComponentMatcher) match(storage *ComponentStorage) *SparseSet[uint
 	for i, t := range m.Components {
 		set := storage.getComponentSet(t)
 		if result.IsEmpty() && i == 0 {
-			result = result.UnionId(set.components.CopyId())
+			result = result.UnionId(set.copyId())
 		} else {
-			result = result.IntersectId(set.components.CopyId())
+			result = result.IntersectId(set.copyId())
 		}
 	}
 	return result
@@ -51,7 +52,7 @@ func (m *AnyOfComponentMatcher) match(storage *ComponentStorage) *SparseSet[uint
 	result := NewSparseSet[uint32](0)
 	for _, t := range m.Components {
 		set := storage.getComponentSet(t)
-		result = result.UnionId(set.components.CopyId())
+		result = result.UnionId(set.copyId())
 	}
 	return result
 }
@@ -61,7 +62,7 @@ func (m *NoneOfComponentMatcher) match(storage *ComponentStorage) *SparseSet[uin
 	result = result.UnionId(storage.entities.CopyId())
 	for _, t := range m.Components {
 		set := storage.getComponentSet(t)
-		result = result.DifferenceId(set.components.CopyId())
+		result = result.DifferenceId(set.copyId())
 	}
 	return result
 }
@@ -103,7 +104,7 @@ func (m *AllOfComponentMatcher) matchOne(storage *ComponentStorage, entity uint3
 	}
 	for _, t := range m.Components {
 		set := storage.getComponentSet(t)
-		if !set.components.Contains(entity) {
+		if !set.contains(entity) {
 			result.Remove(entity)
 			return result
 		}
@@ -119,7 +120,7 @@ func (m *AnyOfComponentMatcher) matchOne(storage *ComponentStorage, entity uint3
 	}
 	for _, t := range m.Components {
 		set := storage.getComponentSet(t)
-		if set.components.Contains(entity) {
+		if set.contains(entity) {
 			result.Insert(entity, entity)
 			return result
 		}
@@ -132,7 +133,7 @@ func (m *NoneOfComponentMatcher) matchOne(storage *ComponentStorage, entity uint
 	result := NewSparseSet[uint32](entity + 1)
 	for _, t := range m.Components {
 		set := storage.getComponentSet(t)
-		if set.components.Contains(entity) {
+		if set.contains(entity) {
 			result.Remove(entity)
 			return result
 		}
@@ -173,6 +174,7 @@ func (m *NoneOfMatcher) matchOne(storage *ComponentStorage, entity uint32) *Spar
 type Group struct {
 	matcher       Matcher
 	result        *SparseSet[uint32]
+	changes       atomic.Int64
 	EntityAdded   chan uint32
 	EntityRemoved chan uint32
 }
@@ -207,6 +209,7 @@ func (g *Group) EvaluateEntity(entity uint32, storage *ComponentStorage, wg *syn
 		if !storage.entities.Contains(entity) {
 			if g.result.Contains(entity) {
 				g.result.Remove(entity)
+				g.changes.Add(1)
 				select {
 				case g.EntityRemoved <- entity:
 				default:
@@ -223,6 +226,7 @@ func (g *Group) EvaluateEntity(entity uint32, storage *ComponentStorage, wg *syn
 			return
 		}
 
+		g.changes.Add(1)
 		if result.Contains(entity) {
 			g.result.Insert(entity, entity)
 			select {
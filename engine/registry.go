@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// The component registry gives component types stable names so they can be
+// written to snapshots and read back by a later build of the game.
+var componentRegistry = struct {
+	mu    sync.RWMutex
+	types map[string]reflect.Type
+	names map[reflect.Type]string
+}{
+	types: map[string]reflect.Type{},
+	names: map[reflect.Type]string{},
+}
+
+func init() {
+	RegisterComponent[AudioSource]("engine.AudioSource")
+}
+
+// RegisterComponent names the component type T. Only registered components
+// are saved in snapshots. Registering a name twice for different types
+// panics.
+func RegisterComponent[T any](name string) {
+	t := reflect.TypeFor[T]()
+	componentRegistry.mu.Lock()
+	defer componentRegistry.mu.Unlock()
+	if existing, ok := componentRegistry.types[name]; ok && existing != t {
+		panic(fmt.Sprintf("component name %q registered for both %s and %s", name, existing, t))
+	}
+	if existing, ok := componentRegistry.names[t]; ok && existing != name {
+		panic(fmt.Sprintf("component %s registered as both %q and %q", t, existing, name))
+	}
+	componentRegistry.types[name] = t
+	componentRegistry.names[t] = name
+}
+
+// RegisterResource names the resource type T. Only registered resources are
+// saved in snapshots. Resources and components share one set of names.
+func RegisterResource[T any](name string) {
+	RegisterComponent[T](name)
+}
+
+// ComponentName returns the name t was registered with.
+func ComponentName(t reflect.Type) (string, bool) {
+	componentRegistry.mu.RLock()
+	defer componentRegistry.mu.RUnlock()
+	name, ok := componentRegistry.names[t]
+	return name, ok
+}
+
+// ComponentType returns the type registered as name.
+func ComponentType(name string) (reflect.Type, bool) {
+	componentRegistry.mu.RLock()
+	defer componentRegistry.mu.RUnlock()
+	t, ok := componentRegistry.types[name]
+	return t, ok
+}
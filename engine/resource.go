@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrResourceNotFound is returned when a resource has not been inserted.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// Resources are values the world holds once, such as the input state, the
+// time or a level's tilemap. They live outside the component storage and are
+// looked up by type. Resources outlive ReplaceScene and Reset, so a scene
+// inserts the ones it needs in Setup, replacing those left by the scene
+// before. A pushed scene starts with the resources of the scene below it, and
+// the ones it inserts go away when it is popped.
+
+// InsertResource stores value as the world's resource of type T, replacing
+// any previous one.
+func InsertResource[T any](world *World, value T) {
+	world.resources[reflect.TypeFor[T]()] = &value
+}
+
+// Resource returns a copy of the world's resource of type T.
+func Resource[T any](world *World) (T, error) {
+	value, err := ResourceMut[T](world)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return *value, nil
+}
+
+// ResourceMut returns the world's resource of type T for changing in place.
+func ResourceMut[T any](world *World) (*T, error) {
+	t := reflect.TypeFor[T]()
+	value, ok := world.resources[t]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s, insert it with InsertResource first", ErrResourceNotFound, t)
+	}
+	return value.(*T), nil
+}
+
+func HasResource[T any](world *World) bool {
+	_, ok := world.resources[reflect.TypeFor[T]()]
+	return ok
+}
+
+func RemoveResource[T any](world *World) {
+	delete(world.resources, reflect.TypeFor[T]())
+}
+
+// insertResource stores value, which must not be a pointer, under its type.
+func (world *World) insertResource(value reflect.Value) {
+	resource := reflect.New(value.Type())
+	resource.Elem().Set(value)
+	world.resources[value.Type()] = resource.Interface()
+}
+
+// cloneResources deep copies resources, so changing the copies leaves them
+// as they are.
+func cloneResources(resources map[reflect.Type]any) map[reflect.Type]any {
+	clone := make(map[reflect.Type]any, len(resources))
+	for t, resource := range resources {
+		clone[t] = deepCopy(reflect.ValueOf(resource)).Interface()
+	}
+	return clone
+}
+
+type namedResource struct {
+	name  string
+	value any
+}
+
+// namedResources returns the values of the registered resources, sorted by
+// name.
+func (world *World) namedResources() []namedResource {
+	var resources []namedResource
+	for t, resource := range world.resources {
+		if name, ok := ComponentName(t); ok {
+			resources = append(resources, namedResource{name: name, value: reflect.ValueOf(resource).Elem().Interface()})
+		}
+	}
+	slices.SortFunc(resources, func(a, b namedResource) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return resources
+}
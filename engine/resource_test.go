@@ -0,0 +1,104 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type savedScore struct {
+	Points int
+	Log    []string
+}
+
+type unsavedCache struct {
+	Hits int
+}
+
+func init() {
+	RegisterResource[savedScore]("test.Score")
+}
+
+func TestResources(t *testing.T) {
+	world := newWorld()
+	_, err := Resource[savedScore](world)
+	assert.ErrorIs(t, err, ErrResourceNotFound)
+	assert.ErrorContains(t, err, "engine.savedScore")
+	assert.False(t, HasResource[savedScore](world))
+
+	InsertResource(world, savedScore{Points: 1})
+	score, err := ResourceMut[savedScore](world)
+	require.NoError(t, err)
+	score.Points++
+	value, err := Resource[savedScore](world)
+	require.NoError(t, err)
+	assert.Equal(t, 2, value.Points)
+
+	InsertResource(world, savedScore{Points: 5})
+	value, _ = Resource[savedScore](world)
+	assert.Equal(t, 5, value.Points)
+
+	RemoveResource[savedScore](world)
+	assert.False(t, HasResource[savedScore](world))
+
+	assert.True(t, HasResource[Time](world))
+	assert.True(t, HasResource[Input](world))
+}
+
+func TestResourcesInSnapshots(t *testing.T) {
+	world := newWorld()
+	InsertResource(world, savedScore{Points: 3, Log: []string{"door"}})
+	InsertResource(world, unsavedCache{Hits: 1})
+	snapshot, err := world.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, []any{savedScore{Points: 3, Log: []string{"door"}}}, snapshot.Resources)
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var fromJSON Snapshot
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	data, err = snapshot.MarshalBinary()
+	require.NoError(t, err)
+	var fromBinary Snapshot
+	require.NoError(t, fromBinary.UnmarshalBinary(data))
+
+	for _, loaded := range []*Snapshot{&fromJSON, &fromBinary} {
+		restored := newWorld()
+		InsertResource(restored, unsavedCache{Hits: 9})
+		require.NoError(t, restored.Restore(loaded))
+		score, err := Resource[savedScore](restored)
+		require.NoError(t, err)
+		assert.Equal(t, savedScore{Points: 3, Log: []string{"door"}}, score)
+		cache, _ := Resource[unsavedCache](restored)
+		assert.Equal(t, 9, cache.Hits, "resources that were not saved are kept")
+		assert.Equal(t, world.Hash(), restored.Hash())
+	}
+
+	clone := world.Clone()
+	score, _ := ResourceMut[savedScore](clone)
+	score.Log[0] = "changed"
+	original, _ := Resource[savedScore](world)
+	assert.Equal(t, "door", original.Log[0])
+	assert.NotEqual(t, world.Hash(), clone.Hash())
+}
+
+func TestPushedScenesShareResources(t *testing.T) {
+	world := newWorld()
+	InsertResource(world, savedScore{Points: 1})
+	world.PushScene(&testScene{name: "level", system: &countingSystem{}})
+	require.NoError(t, world.applySceneTransitions())
+	world.PushScene(&testScene{name: "pause", system: &countingSystem{}})
+	require.NoError(t, world.applySceneTransitions())
+
+	score, err := Resource[savedScore](world)
+	require.NoError(t, err)
+	assert.Equal(t, 1, score.Points)
+	InsertResource(world, unsavedCache{})
+
+	world.PopScene()
+	require.NoError(t, world.applySceneTransitions())
+	assert.False(t, HasResource[unsavedCache](world))
+	assert.True(t, HasResource[savedScore](world))
+}
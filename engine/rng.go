@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"reflect"
+	"time"
+)
+
+// RNG is the world's source of randomness. Every user draws from its own
+// named stream, derived from the seed and the name, so adding a new user of
+// randomness does not change the numbers the others get. Runs with the same
+// seed and inputs are reproducible.
+type RNG struct {
+	seed    uint64
+	streams map[string]*rngStream
+}
+
+type rngStream struct {
+	source *rand.PCG
+	rand   *rand.Rand
+}
+
+func NewRNG(seed uint64) *RNG {
+	return &RNG{seed: seed, streams: map[string]*rngStream{}}
+}
+
+// newSeed picks a seed for worlds that were not given one.
+func newSeed() uint64 {
+	return uint64(time.Now().UnixNano())
+}
+
+func (rng *RNG) Seed() uint64 {
+	return rng.seed
+}
+
+// Reseed restarts every stream from seed. Streams handed out earlier stay
+// valid and continue with the new sequence.
+func (rng *RNG) Reseed(seed uint64) {
+	rng.seed = seed
+	for name, stream := range rng.streams {
+		stream.source.Seed(seed, streamSeed(name))
+	}
+}
+
+// Stream returns the named stream, creating it if needed. Streams are not
+// safe for concurrent use.
+func (rng *RNG) Stream(name string) *rand.Rand {
+	stream, ok := rng.streams[name]
+	if !ok {
+		source := rand.NewPCG(rng.seed, streamSeed(name))
+		stream = &rngStream{source: source, rand: rand.New(source)}
+		rng.streams[name] = stream
+	}
+	return stream.rand
+}
+
+// For returns the stream of system, named after its type.
+func (rng *RNG) For(system System) *rand.Rand {
+	return rng.Stream(reflect.TypeOf(system).String())
+}
+
+// state returns the position of every stream, keyed by name.
+func (rng *RNG) state() map[string][]byte {
+	state := make(map[string][]byte, len(rng.streams))
+	for name, stream := range rng.streams {
+		data, _ := stream.source.MarshalBinary()
+		state[name] = data
+	}
+	return state
+}
+
+// restore reseeds the streams and moves the ones in state to where they were.
+func (rng *RNG) restore(seed uint64, state map[string][]byte) error {
+	rng.Reseed(seed)
+	for name, data := range state {
+		rng.Stream(name)
+		if err := rng.streams[name].source.UnmarshalBinary(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (rng *RNG) clone() *RNG {
+	clone := NewRNG(rng.seed)
+	_ = clone.restore(rng.seed, rng.state())
+	return clone
+}
+
+func streamSeed(name string) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(name))
+	return hash.Sum64()
+}
@@ -0,0 +1,72 @@
+package engine
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drawNumbers(stream interface{ Uint64() uint64 }, n int) []uint64 {
+	values := make([]uint64, n)
+	for i := range values {
+		values[i] = stream.Uint64()
+	}
+	return values
+}
+
+func TestRNGStreams(t *testing.T) {
+	first := NewRNG(42)
+	second := NewRNG(42)
+
+	// Drawing from another stream first does not change the sequence.
+	drawNumbers(second.Stream("enemies"), 10)
+	assert.Equal(t, drawNumbers(first.Stream("loot"), 5), drawNumbers(second.Stream("loot"), 5))
+	assert.NotEqual(t, drawNumbers(first.Stream("loot"), 5), drawNumbers(first.Stream("enemies"), 5))
+	assert.Same(t, first.For(&walkSystem{}), first.Stream("*engine.walkSystem"))
+
+	stream := first.Stream("loot")
+	first.Reseed(42)
+	assert.Equal(t, drawNumbers(NewRNG(42).Stream("loot"), 5), drawNumbers(stream, 5), "reseeding restarts existing streams")
+	assert.NotEqual(t, drawNumbers(NewRNG(42).Stream("loot"), 5), drawNumbers(NewRNG(43).Stream("loot"), 5))
+}
+
+func TestRNGInSnapshots(t *testing.T) {
+	world := newWorld()
+	world.RNG.Reseed(7)
+	drawNumbers(world.RNG.Stream("loot"), 3)
+	snapshot, err := world.Snapshot()
+	require.NoError(t, err)
+	expected := drawNumbers(world.RNG.Stream("loot"), 5)
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var fromJSON Snapshot
+	require.NoError(t, json.Unmarshal(data, &fromJSON))
+	data, err = snapshot.MarshalBinary()
+	require.NoError(t, err)
+	var fromBinary Snapshot
+	require.NoError(t, fromBinary.UnmarshalBinary(data))
+
+	for _, loaded := range []*Snapshot{&fromJSON, &fromBinary} {
+		restored := newWorld()
+		require.NoError(t, restored.Restore(loaded))
+		assert.Equal(t, uint64(7), restored.RNG.Seed())
+		assert.Equal(t, expected, drawNumbers(restored.RNG.Stream("loot"), 5), "streams continue where they were saved")
+	}
+
+	clone := world.Clone()
+	assert.Equal(t, drawNumbers(world.RNG.Stream("loot"), 5), drawNumbers(clone.RNG.Stream("loot"), 5))
+
+	failed := newWorld()
+	failed.RNG.Reseed(9)
+	broken := *snapshot
+	broken.Entities = []EntitySnapshot{{ID: MaxEntities, Components: []any{ComponentA{}}}}
+	assert.Error(t, failed.Restore(&broken))
+	assert.Equal(t, uint64(9), failed.RNG.Seed(), "a failed restore leaves the RNG alone")
+	broken = *snapshot
+	broken.Streams = map[string][]byte{"loot": []byte("garbage")}
+	assert.Error(t, failed.Restore(&broken))
+	assert.Equal(t, uint64(9), failed.RNG.Seed())
+}
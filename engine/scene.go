@@ -0,0 +1,134 @@
+package engine
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Scene is one state of the game, such as a menu or a level. Each scene gets
+// its own entities and systems while the window, audio and assets belong to
+// the World and persist across scenes.
+type Scene interface {
+	// Systems returns the systems that run while the scene is on top.
+	Systems() []System
+	// Setup creates the scene's entities. It runs before the systems are
+	// initialized.
+	Setup(world *World) error
+}
+
+type sceneTransitionKind int
+
+const (
+	pushScene sceneTransitionKind = iota
+	popScene
+	replaceScene
+)
+
+type sceneTransition struct {
+	kind  sceneTransitionKind
+	scene Scene
+}
+
+// sceneFrame holds the state of a scene that is covered by another one.
+type sceneFrame struct {
+	scene      Scene
+	systems    map[SystemType][]System
+	components *ComponentStorage
+	groups     map[Matcher]*Group
+	history    *History
+	resources  map[reflect.Type]any
+}
+
+// PushScene suspends the current scene and enters scene on top of it once the
+// current frame finishes.
+func (world *World) PushScene(scene Scene) {
+	world.transitions = append(world.transitions, sceneTransition{kind: pushScene, scene: scene})
+}
+
+// PopScene leaves the current scene once the current frame finishes and
+// resumes the one below it. Popping the last scene stops the world.
+func (world *World) PopScene() {
+	world.transitions = append(world.transitions, sceneTransition{kind: popScene})
+}
+
+// ReplaceScene leaves the current scene and enters scene in its place once
+// the current frame finishes.
+func (world *World) ReplaceScene(scene Scene) {
+	world.transitions = append(world.transitions, sceneTransition{kind: replaceScene, scene: scene})
+}
+
+// Scene returns the scene on top of the stack, or nil.
+func (world *World) Scene() Scene {
+	return world.scene
+}
+
+func (world *World) applySceneTransitions() error {
+	for len(world.transitions) > 0 {
+		transition := world.transitions[0]
+		world.transitions = world.transitions[1:]
+		// Events were meant for the scene that sent them.
+		world.clearEvents()
+		switch transition.kind {
+		case pushScene:
+			if world.scene != nil {
+				world.scenes = append(world.scenes, &sceneFrame{
+					scene:      world.scene,
+					systems:    world.systems,
+					components: world.components,
+					groups:     world.groups,
+					history:    world.history,
+					resources:  world.resources,
+				})
+				world.resources = cloneResources(world.resources)
+			}
+			if err := world.enterScene(transition.scene); err != nil {
+				return err
+			}
+		case popScene:
+			world.exitScene()
+			if len(world.scenes) == 0 {
+				world.running = false
+				return nil
+			}
+			frame := world.scenes[len(world.scenes)-1]
+			world.scenes = world.scenes[:len(world.scenes)-1]
+			world.scene = frame.scene
+			world.systems = frame.systems
+			world.components = frame.components
+			world.groups = frame.groups
+			world.history = frame.history
+			world.resources = frame.resources
+			world.reindex()
+		case replaceScene:
+			world.exitScene()
+			if err := world.enterScene(transition.scene); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (world *World) enterScene(scene Scene) error {
+	world.scene = scene
+	world.systems = map[SystemType][]System{}
+	world.components = NewComponentStorage()
+	world.groups = make(map[Matcher]*Group)
+	world.history = nil
+	world.reindex()
+	if err := scene.Setup(world); err != nil {
+		return fmt.Errorf("setting up scene %s: %w", reflect.TypeOf(scene), err)
+	}
+	world.AddSystems(scene.Systems()...)
+	world.initialize()
+	return nil
+}
+
+func (world *World) exitScene() {
+	if world.scene == nil {
+		return
+	}
+	world.resetSystems()
+	world.Assets.Collect()
+	world.scene = nil
+}
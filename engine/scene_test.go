@@ -0,0 +1,110 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sceneMarker struct {
+	Name string
+}
+
+type countingSystem struct {
+	initialized int
+	closed      int
+}
+
+func (system *countingSystem) Initialize(world *World) error {
+	system.initialized++
+	return nil
+}
+
+func (system *countingSystem) Close() error {
+	system.closed++
+	return nil
+}
+
+type testScene struct {
+	name   string
+	system *countingSystem
+	err    error
+}
+
+func (scene *testScene) Systems() []System {
+	return []System{scene.system}
+}
+
+func (scene *testScene) Setup(world *World) error {
+	world.CreateEntity(sceneMarker{Name: scene.name})
+	return scene.err
+}
+
+func sceneMarkerName(world *World) string {
+	return world.GetUniqueComponent(reflect.TypeOf(sceneMarker{})).(sceneMarker).Name
+}
+
+func TestSceneStack(t *testing.T) {
+	world := newWorld()
+	menu := &testScene{name: "menu", system: &countingSystem{}}
+	level := &testScene{name: "level", system: &countingSystem{}}
+
+	world.PushScene(menu)
+	assert.Nil(t, world.Scene(), "transitions wait for the end of the frame")
+	assert.NoError(t, world.applySceneTransitions())
+	assert.Equal(t, menu, world.Scene())
+	assert.Equal(t, "menu", sceneMarkerName(world))
+
+	world.PushScene(level)
+	assert.NoError(t, world.applySceneTransitions())
+	assert.Equal(t, level, world.Scene())
+	assert.Equal(t, "level", sceneMarkerName(world))
+	assert.Equal(t, 0, menu.system.closed, "covered scenes are suspended, not closed")
+
+	world.PopScene()
+	assert.NoError(t, world.applySceneTransitions())
+	assert.Equal(t, menu, world.Scene())
+	assert.Equal(t, "menu", sceneMarkerName(world))
+	assert.Equal(t, 1, level.system.closed)
+	assert.Equal(t, 1, menu.system.initialized, "resumed scenes are not set up again")
+
+	world.ReplaceScene(level)
+	assert.NoError(t, world.applySceneTransitions())
+	assert.Equal(t, 1, menu.system.closed)
+	assert.Equal(t, 2, level.system.initialized)
+
+	world.PopScene()
+	assert.NoError(t, world.applySceneTransitions())
+	assert.Nil(t, world.Scene())
+	assert.False(t, world.running, "popping the last scene stops the world")
+}
+
+func TestSceneSetupError(t *testing.T) {
+	world := newWorld()
+	failed := errors.New("missing level")
+	world.PushScene(&testScene{name: "broken", system: &countingSystem{}, err: failed})
+	assert.ErrorIs(t, world.applySceneTransitions(), failed)
+}
+
+func TestPoppingUndoesResourceChanges(t *testing.T) {
+	world := newWorld()
+	InsertResource(world, savedScore{Points: 1, Log: []string{"door"}})
+	world.PushScene(&testScene{name: "level", system: &countingSystem{}})
+	require.NoError(t, world.applySceneTransitions())
+	world.PushScene(&testScene{name: "pause", system: &countingSystem{}})
+	require.NoError(t, world.applySceneTransitions())
+
+	score, err := ResourceMut[savedScore](world)
+	require.NoError(t, err)
+	score.Points = 2
+	score.Log[0] = "button"
+
+	world.PopScene()
+	require.NoError(t, world.applySceneTransitions())
+	covered, err := Resource[savedScore](world)
+	require.NoError(t, err)
+	assert.Equal(t, savedScore{Points: 1, Log: []string{"door"}}, covered)
+}
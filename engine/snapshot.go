@@ -0,0 +1,432 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	snapshotMagic   = "PKSN"
+	snapshotVersion = 1
+)
+
+// Snapshot is a copy of every entity in a World with its registered
+// components, and of the registered resources. Components whose type is not
+// registered, such as one-off requests, are left out, and so are entities
+// left without components.
+//
+// Snapshots marshal to JSON and, through MarshalBinary, to a compact binary
+// format. Both only keep exported struct fields.
+type Snapshot struct {
+	// Next is the id the world would give its next entity.
+	Next uint32
+	// Seed and Streams are the world's RNG seed and the position of each of
+	// its streams. Streams is nil in snapshots saved without them.
+	Seed     uint64
+	Streams  map[string][]byte
+	Entities []EntitySnapshot
+	// Resources holds the values of the registered resources.
+	Resources []any
+}
+
+type EntitySnapshot struct {
+	ID         uint32
+	Components []any
+}
+
+// Snapshot copies the world's entities. It fails if a registered component
+// holds something that cannot be saved, such as a func.
+func (world *World) Snapshot() (*Snapshot, error) {
+	storage := world.components
+	sets := storage.namedSets()
+	entities := storage.sortedEntities()
+
+	snapshot := &Snapshot{Next: storage.entityIndex}
+	if world.RNG != nil {
+		snapshot.Seed = world.RNG.Seed()
+		snapshot.Streams = world.RNG.state()
+	}
+	for _, entity := range entities {
+		var components []any
+		for _, set := range sets {
+			component, ok := set.set.get(entity)
+			if !ok {
+				continue
+			}
+			clone, err := cloneComponent(component)
+			if err != nil {
+				return nil, fmt.Errorf("saving %s of entity %s: %w", set.name, storage.names.ref(entity), err)
+			}
+			components = append(components, clone)
+		}
+		if len(components) > 0 {
+			snapshot.Entities = append(snapshot.Entities, EntitySnapshot{ID: entity, Components: components})
+		}
+	}
+	for _, resource := range world.namedResources() {
+		clone, err := cloneComponent(resource.value)
+		if err != nil {
+			return nil, fmt.Errorf("saving resource %s: %w", resource.name, err)
+		}
+		snapshot.Resources = append(snapshot.Resources, clone)
+	}
+	return snapshot, nil
+}
+
+type namedSet struct {
+	name string
+	set  *ComponentSet[any]
+}
+
+// namedSets returns the sets of the registered components, sorted by name.
+func (storage *ComponentStorage) namedSets() []namedSet {
+	var sets []namedSet
+	for t, idx := range storage.registry {
+		if name, ok := ComponentName(t); ok {
+			sets = append(sets, namedSet{name: name, set: &storage.componentSets[idx]})
+		}
+	}
+	slices.SortFunc(sets, func(a, b namedSet) int {
+		return strings.Compare(a.name, b.name)
+	})
+	return sets
+}
+
+func (storage *ComponentStorage) sortedEntities() []uint32 {
+	entities := make([]uint32, 0, storage.entities.Len())
+	iterator := storage.entities.Iterator()
+	for {
+		id, _, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		entities = append(entities, id)
+	}
+	slices.Sort(entities)
+	return entities
+}
+
+// Restore replaces the entities of the current scene with the ones in
+// snapshot, keeping their ids, inserts the saved resources and puts the RNG
+// back where it was, unless the snapshot has no RNG state. Resources that
+// were not saved are kept. Existing groups are re-evaluated against the
+// restored entities without sending EntityAdded or EntityRemoved, and the
+// scene's history is cleared.
+func (world *World) Restore(snapshot *Snapshot) error {
+	storage := NewComponentStorage()
+	next := snapshot.Next
+	for _, entity := range snapshot.Entities {
+		if entity.ID >= MaxEntities {
+			return fmt.Errorf("restoring entity %s: id out of range", snapshotRef(entity))
+		}
+		if storage.entities.Contains(entity.ID) {
+			return fmt.Errorf("restoring entity %s: duplicate id", snapshotRef(entity))
+		}
+		components := make([]any, len(entity.Components))
+		for i, component := range entity.Components {
+			clone, err := cloneComponent(component)
+			if err != nil {
+				return fmt.Errorf("restoring %T of entity %s: %w", component, snapshotRef(entity), err)
+			}
+			components[i] = clone
+		}
+		storage.insertEntity(entity.ID, components...)
+		next = max(next, entity.ID+1)
+	}
+	storage.entityIndex = next
+
+	resources := make([]reflect.Value, len(snapshot.Resources))
+	for i, resource := range snapshot.Resources {
+		clone, err := cloneComponent(resource)
+		if err != nil {
+			return fmt.Errorf("restoring resource %T: %w", resource, err)
+		}
+		resources[i] = reflect.ValueOf(clone)
+	}
+	if snapshot.Streams != nil {
+		if err := NewRNG(snapshot.Seed).restore(snapshot.Seed, snapshot.Streams); err != nil {
+			return fmt.Errorf("restoring random streams: %w", err)
+		}
+	}
+
+	world.components = storage
+	for _, resource := range resources {
+		world.insertResource(resource)
+	}
+	if world.history != nil {
+		world.history.Clear()
+	}
+	for _, group := range world.groups {
+		group.result = group.matcher.match(storage)
+	}
+	world.reindex()
+	if world.RNG != nil && snapshot.Streams != nil {
+		_ = world.RNG.restore(snapshot.Seed, snapshot.Streams)
+	}
+	return nil
+}
+
+type jsonSnapshot struct {
+	Next      uint32                     `json:"next"`
+	Seed      uint64                     `json:"seed"`
+	Streams   map[string][]byte          `json:"streams"`
+	Entities  []jsonEntity               `json:"entities"`
+	Resources map[string]json.RawMessage `json:"resources,omitempty"`
+}
+
+type jsonEntity struct {
+	ID         uint32                     `json:"id"`
+	Components map[string]json.RawMessage `json:"components"`
+}
+
+func (snapshot *Snapshot) MarshalJSON() ([]byte, error) {
+	data := jsonSnapshot{
+		Next:     snapshot.Next,
+		Seed:     snapshot.Seed,
+		Streams:  snapshot.Streams,
+		Entities: make([]jsonEntity, 0, len(snapshot.Entities)),
+	}
+	for _, entity := range snapshot.Entities {
+		components := make(map[string]json.RawMessage, len(entity.Components))
+		for _, component := range entity.Components {
+			name, ok := ComponentName(reflect.TypeOf(component))
+			if !ok {
+				return nil, fmt.Errorf("component %T is not registered", component)
+			}
+			raw, err := json.Marshal(component)
+			if err != nil {
+				return nil, fmt.Errorf("saving %s of entity %s: %w", name, snapshotRef(entity), err)
+			}
+			components[name] = raw
+		}
+		data.Entities = append(data.Entities, jsonEntity{ID: entity.ID, Components: components})
+	}
+	if len(snapshot.Resources) > 0 {
+		data.Resources = make(map[string]json.RawMessage, len(snapshot.Resources))
+	}
+	for _, resource := range snapshot.Resources {
+		name, ok := ComponentName(reflect.TypeOf(resource))
+		if !ok {
+			return nil, fmt.Errorf("resource %T is not registered", resource)
+		}
+		raw, err := json.Marshal(resource)
+		if err != nil {
+			return nil, fmt.Errorf("saving resource %s: %w", name, err)
+		}
+		data.Resources[name] = raw
+	}
+	return json.Marshal(data)
+}
+
+func (snapshot *Snapshot) UnmarshalJSON(b []byte) error {
+	var data jsonSnapshot
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	result := Snapshot{Next: data.Next, Seed: data.Seed, Streams: data.Streams}
+	for _, entity := range data.Entities {
+		names := make([]string, 0, len(entity.Components))
+		for name := range entity.Components {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		ref := entityRef{id: entity.ID}
+		if name, ok := ComponentName(nameType); ok {
+			_ = json.Unmarshal(entity.Components[name], &ref.name)
+		}
+		components := make([]any, 0, len(names))
+		for _, name := range names {
+			t, ok := ComponentType(name)
+			if !ok {
+				return fmt.Errorf("loading entity %s: unknown component %q", ref, name)
+			}
+			component := reflect.New(t)
+			if err := json.Unmarshal(entity.Components[name], component.Interface()); err != nil {
+				return fmt.Errorf("loading %s of entity %s: %w", name, ref, err)
+			}
+			components = append(components, component.Elem().Interface())
+		}
+		result.Entities = append(result.Entities, EntitySnapshot{ID: entity.ID, Components: components})
+	}
+	for _, name := range slices.Sorted(maps.Keys(data.Resources)) {
+		t, ok := ComponentType(name)
+		if !ok {
+			return fmt.Errorf("loading unknown resource %q", name)
+		}
+		resource := reflect.New(t)
+		if err := json.Unmarshal(data.Resources[name], resource.Interface()); err != nil {
+			return fmt.Errorf("loading resource %s: %w", name, err)
+		}
+		result.Resources = append(result.Resources, resource.Elem().Interface())
+	}
+	*snapshot = result
+	return nil
+}
+
+// MarshalBinary writes the snapshot in the binary format: a header with the
+// RNG state, a table of the component names used, then every entity with its
+// components.
+func (snapshot *Snapshot) MarshalBinary() ([]byte, error) {
+	var names []string
+	indices := map[string]int{}
+	body := &binaryEncoder{}
+	// write writes the index of value's name followed by value.
+	write := func(value any) (string, error) {
+		name, ok := ComponentName(reflect.TypeOf(value))
+		if !ok {
+			return "", fmt.Errorf("%T is not registered", value)
+		}
+		index, ok := indices[name]
+		if !ok {
+			index = len(names)
+			indices[name] = index
+			names = append(names, name)
+		}
+		body.uvarint(uint64(index))
+		return name, body.value(reflect.ValueOf(value))
+	}
+	body.uvarint(uint64(len(snapshot.Entities)))
+	for _, entity := range snapshot.Entities {
+		body.uvarint(uint64(entity.ID))
+		body.uvarint(uint64(len(entity.Components)))
+		for _, component := range entity.Components {
+			if name, err := write(component); err != nil {
+				return nil, fmt.Errorf("saving %s of entity %s: %w", name, snapshotRef(entity), err)
+			}
+		}
+	}
+	body.uvarint(uint64(len(snapshot.Resources)))
+	for _, resource := range snapshot.Resources {
+		if name, err := write(resource); err != nil {
+			return nil, fmt.Errorf("saving resource %s: %w", name, err)
+		}
+	}
+
+	header := &binaryEncoder{buf: []byte(snapshotMagic)}
+	header.uvarint(snapshotVersion)
+	header.uvarint(uint64(snapshot.Next))
+	header.uvarint(snapshot.Seed)
+	header.uvarint(uint64(len(snapshot.Streams)))
+	for _, name := range slices.Sorted(maps.Keys(snapshot.Streams)) {
+		header.string(name)
+		header.string(string(snapshot.Streams[name]))
+	}
+	header.uvarint(uint64(len(names)))
+	for _, name := range names {
+		header.string(name)
+	}
+	return append(header.buf, body.buf...), nil
+}
+
+func (snapshot *Snapshot) UnmarshalBinary(b []byte) error {
+	if !strings.HasPrefix(string(b), snapshotMagic) {
+		return errors.New("not a snapshot")
+	}
+	decoder := &binaryDecoder{buf: b[len(snapshotMagic):]}
+	version, err := decoder.uvarint()
+	if err != nil {
+		return err
+	}
+	if version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	next, err := decoder.uvarint()
+	if err != nil {
+		return err
+	}
+	seed, err := decoder.uvarint()
+	if err != nil {
+		return err
+	}
+	streamCount, err := decoder.length()
+	if err != nil {
+		return err
+	}
+	streams := make(map[string][]byte, streamCount)
+	for range streamCount {
+		name, err := decoder.string()
+		if err != nil {
+			return err
+		}
+		state, err := decoder.string()
+		if err != nil {
+			return err
+		}
+		streams[name] = []byte(state)
+	}
+	count, err := decoder.length()
+	if err != nil {
+		return err
+	}
+	types := make([]reflect.Type, count)
+	for i := range types {
+		name, err := decoder.string()
+		if err != nil {
+			return err
+		}
+		t, ok := ComponentType(name)
+		if !ok {
+			return fmt.Errorf("unknown component %q", name)
+		}
+		types[i] = t
+	}
+
+	// read reads a value written by MarshalBinary's write.
+	read := func() (reflect.Type, any, error) {
+		index, err := decoder.uvarint()
+		if err != nil {
+			return nil, nil, err
+		}
+		if index >= uint64(len(types)) {
+			return nil, nil, fmt.Errorf("type index %d out of range", index)
+		}
+		value := reflect.New(types[index]).Elem()
+		if err := decoder.value(value); err != nil {
+			return types[index], nil, err
+		}
+		return types[index], value.Interface(), nil
+	}
+
+	result := Snapshot{Next: uint32(next), Seed: seed, Streams: streams}
+	entityCount, err := decoder.length()
+	if err != nil {
+		return err
+	}
+	for range entityCount {
+		id, err := decoder.uvarint()
+		if err != nil {
+			return err
+		}
+		componentCount, err := decoder.length()
+		if err != nil {
+			return err
+		}
+		entity := EntitySnapshot{ID: uint32(id), Components: make([]any, componentCount)}
+		for i := range entity.Components {
+			t, component, err := read()
+			if err != nil {
+				return fmt.Errorf("loading %v of entity %s: %w", t, snapshotRef(entity), err)
+			}
+			entity.Components[i] = component
+		}
+		result.Entities = append(result.Entities, entity)
+	}
+	resourceCount, err := decoder.length()
+	if err != nil {
+		return err
+	}
+	for range resourceCount {
+		t, resource, err := read()
+		if err != nil {
+			return fmt.Errorf("loading resource %v: %w", t, err)
+		}
+		result.Resources = append(result.Resources, resource)
+	}
+	*snapshot = result
+	return nil
+}
@@ -0,0 +1,169 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type savedPosition struct {
+	X, Y int
+}
+
+type savedInventory struct {
+	Items  []string
+	Counts map[string]int
+	Owner  *savedPosition
+	Weight float64
+	hidden int
+}
+
+type savedTag struct{}
+
+type savedCallback struct {
+	Call func()
+}
+
+type unsavedRequest struct {
+	Name string
+}
+
+func init() {
+	RegisterComponent[savedPosition]("test.Position")
+	RegisterComponent[savedInventory]("test.Inventory")
+	RegisterComponent[savedTag]("test.Tag")
+	RegisterComponent[savedCallback]("test.Callback")
+}
+
+var savedPositionMatcher = &AllOfComponentMatcher{Components: []reflect.Type{reflect.TypeOf(savedPosition{})}}
+
+func newSnapshotWorld() *World {
+	world := newWorld()
+	world.CreateEntity(savedPosition{X: 1, Y: 2}, savedTag{})
+	removed := world.CreateEntity(savedPosition{X: 3, Y: 4})
+	world.CreateEntity(
+		savedPosition{X: 5, Y: 6},
+		savedInventory{
+			Items:  []string{"key", "map"},
+			Counts: map[string]int{"key": 1, "map": 2},
+			Owner:  &savedPosition{X: 7, Y: 8},
+			Weight: 1.5,
+			hidden: 9,
+		},
+	)
+	world.CreateEntity(unsavedRequest{Name: "dropped"})
+	world.DeleteEntity(removed)
+	return world
+}
+
+func assertRestored(t *testing.T, world *World) {
+	t.Helper()
+	assert.ElementsMatch(t, []uint32{0, 2}, world.GetGroup(savedPositionMatcher).GetEntities())
+
+	position, ok := world.GetEntityComponent(2, reflect.TypeOf(savedPosition{}))
+	assert.True(t, ok)
+	assert.Equal(t, savedPosition{X: 5, Y: 6}, position)
+	inventory, ok := world.GetEntityComponent(2, reflect.TypeOf(savedInventory{}))
+	assert.True(t, ok)
+	assert.Equal(t, savedInventory{
+		Items:  []string{"key", "map"},
+		Counts: map[string]int{"key": 1, "map": 2},
+		Owner:  &savedPosition{X: 7, Y: 8},
+		Weight: 1.5,
+	}, inventory, "unexported fields are not saved")
+	_, ok = world.GetEntityComponent(0, reflect.TypeOf(savedTag{}))
+	assert.True(t, ok)
+
+	assert.Equal(t, uint32(4), world.CreateEntity(savedTag{}), "restored worlds keep handing out new ids")
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	snapshot, err := newSnapshotWorld().Snapshot()
+	require.NoError(t, err)
+	assert.Len(t, snapshot.Entities, 2, "entities without registered components are skipped")
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var loaded Snapshot
+	require.NoError(t, json.Unmarshal(data, &loaded))
+
+	world := newWorld()
+	group := world.GetGroup(savedPositionMatcher)
+	require.NoError(t, world.Restore(&loaded))
+	assert.Same(t, group, world.GetGroup(savedPositionMatcher), "groups are re-evaluated in place")
+	assertRestored(t, world)
+}
+
+func TestSnapshotBinary(t *testing.T) {
+	snapshot, err := newSnapshotWorld().Snapshot()
+	require.NoError(t, err)
+
+	data, err := snapshot.MarshalBinary()
+	require.NoError(t, err)
+	var loaded Snapshot
+	require.NoError(t, loaded.UnmarshalBinary(data))
+
+	world := newWorld()
+	require.NoError(t, world.Restore(&loaded))
+	assertRestored(t, world)
+
+	jsonData, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	assert.Less(t, len(data), len(jsonData))
+
+	for i := range len(data) {
+		assert.Error(t, new(Snapshot).UnmarshalBinary(data[:i]), "truncated at %d", i)
+	}
+}
+
+func TestSnapshotIsACopy(t *testing.T) {
+	world := newSnapshotWorld()
+	snapshot, err := world.Snapshot()
+	require.NoError(t, err)
+
+	inventory, _ := world.GetEntityComponent(2, reflect.TypeOf(savedInventory{}))
+	inventory.(savedInventory).Items[0] = "changed"
+	require.NoError(t, world.Restore(snapshot))
+	inventory, _ = world.GetEntityComponent(2, reflect.TypeOf(savedInventory{}))
+	inventory.(savedInventory).Items[0] = "changed again"
+
+	require.NoError(t, world.Restore(snapshot))
+	inventory, _ = world.GetEntityComponent(2, reflect.TypeOf(savedInventory{}))
+	assert.Equal(t, "key", inventory.(savedInventory).Items[0])
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	world := newWorld()
+	world.CreateEntity(savedCallback{Call: func() {}})
+	_, err := world.Snapshot()
+	assert.Error(t, err)
+
+	var snapshot Snapshot
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"entities":[{"id":0,"components":{"test.Missing":{}}}]}`), &snapshot), "test.Missing")
+	assert.ErrorContains(t, json.Unmarshal([]byte(`{"entities":[{"id":3,"components":{"engine.Name":"door","test.Position":"left"}}]}`), &snapshot), "entity 3 (door)")
+	assert.Error(t, snapshot.UnmarshalBinary([]byte("nope")))
+
+	assert.Panics(t, func() { RegisterComponent[savedTag]("test.Position") })
+}
+
+func TestSnapshotUnsupportedVersion(t *testing.T) {
+	encoder := &binaryEncoder{buf: []byte(snapshotMagic)}
+	encoder.uvarint(snapshotVersion + 1)
+	assert.ErrorContains(t, new(Snapshot).UnmarshalBinary(encoder.buf), "unsupported snapshot version")
+}
+
+func TestSnapshotBinaryTrailingSlice(t *testing.T) {
+	encoder := &binaryEncoder{}
+	require.NoError(t, encoder.value(reflect.ValueOf([]uint32{1, 2})))
+	require.NoError(t, encoder.value(reflect.ValueOf(map[uint8]bool{})))
+	decoder := &binaryDecoder{buf: encoder.buf}
+	var values []uint32
+	require.NoError(t, decoder.value(reflect.ValueOf(&values).Elem()))
+	assert.Equal(t, []uint32{1, 2}, values)
+	var empty map[uint8]bool
+	require.NoError(t, decoder.value(reflect.ValueOf(&empty).Elem()))
+	assert.NotNil(t, empty, "a slice or map ending the data is not mistaken for a truncated one")
+}
@@ -0,0 +1,295 @@
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// The binary snapshot format writes values field by field without any type
+// information; the reader gets the types from the component registry. Like
+// encoding/json, only exported struct fields are written.
+
+var errShortSnapshot = errors.New("snapshot data is truncated")
+
+type binaryEncoder struct {
+	buf []byte
+}
+
+func (encoder *binaryEncoder) uvarint(v uint64) {
+	encoder.buf = binary.AppendUvarint(encoder.buf, v)
+}
+
+func (encoder *binaryEncoder) varint(v int64) {
+	encoder.buf = binary.AppendVarint(encoder.buf, v)
+}
+
+func (encoder *binaryEncoder) string(s string) {
+	encoder.uvarint(uint64(len(s)))
+	encoder.buf = append(encoder.buf, s...)
+}
+
+func (encoder *binaryEncoder) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			encoder.buf = append(encoder.buf, 1)
+		} else {
+			encoder.buf = append(encoder.buf, 0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		encoder.varint(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		encoder.uvarint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		encoder.buf = binary.LittleEndian.AppendUint64(encoder.buf, math.Float64bits(v.Float()))
+	case reflect.String:
+		encoder.string(v.String())
+	case reflect.Slice:
+		// Lengths are written plus one so nil and empty slices stay apart.
+		if v.IsNil() {
+			encoder.uvarint(0)
+			return nil
+		}
+		encoder.uvarint(uint64(v.Len()) + 1)
+		for i := range v.Len() {
+			if err := encoder.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		for i := range v.Len() {
+			if err := encoder.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			encoder.uvarint(0)
+			return nil
+		}
+		encoder.uvarint(uint64(v.Len()) + 1)
+		// Keys are sorted by their encoding so equal maps encode equally.
+		type entry struct {
+			key   []byte
+			value reflect.Value
+		}
+		entries := make([]entry, 0, v.Len())
+		iterator := v.MapRange()
+		for iterator.Next() {
+			key := &binaryEncoder{}
+			if err := key.value(iterator.Key()); err != nil {
+				return err
+			}
+			entries = append(entries, entry{key: key.buf, value: iterator.Value()})
+		}
+		slices.SortFunc(entries, func(a, b entry) int {
+			return bytes.Compare(a.key, b.key)
+		})
+		for _, entry := range entries {
+			encoder.buf = append(encoder.buf, entry.key...)
+			if err := encoder.value(entry.value); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if err := encoder.value(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			encoder.buf = append(encoder.buf, 0)
+			return nil
+		}
+		encoder.buf = append(encoder.buf, 1)
+		return encoder.value(v.Elem())
+	default:
+		return fmt.Errorf("cannot encode %s", v.Type())
+	}
+	return nil
+}
+
+type binaryDecoder struct {
+	buf []byte
+}
+
+func (decoder *binaryDecoder) byte() (byte, error) {
+	if len(decoder.buf) == 0 {
+		return 0, errShortSnapshot
+	}
+	b := decoder.buf[0]
+	decoder.buf = decoder.buf[1:]
+	return b, nil
+}
+
+func (decoder *binaryDecoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(decoder.buf)
+	if n <= 0 {
+		return 0, errShortSnapshot
+	}
+	decoder.buf = decoder.buf[n:]
+	return v, nil
+}
+
+func (decoder *binaryDecoder) varint() (int64, error) {
+	v, n := binary.Varint(decoder.buf)
+	if n <= 0 {
+		return 0, errShortSnapshot
+	}
+	decoder.buf = decoder.buf[n:]
+	return v, nil
+}
+
+// length reads a length and checks it against the remaining data, so corrupt
+// input cannot make the decoder allocate huge slices.
+func (decoder *binaryDecoder) length() (int, error) {
+	n, err := decoder.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(decoder.buf)) {
+		return 0, errShortSnapshot
+	}
+	return int(n), nil
+}
+
+// count reads a slice or map length written plus one, so 0 means nil. It is
+// checked like length.
+func (decoder *binaryDecoder) count() (int, error) {
+	n, err := decoder.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(decoder.buf))+1 {
+		return 0, errShortSnapshot
+	}
+	return int(n), nil
+}
+
+func (decoder *binaryDecoder) string() (string, error) {
+	n, err := decoder.length()
+	if err != nil {
+		return "", err
+	}
+	s := string(decoder.buf[:n])
+	decoder.buf = decoder.buf[n:]
+	return s, nil
+}
+
+func (decoder *binaryDecoder) value(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := decoder.byte()
+		if err != nil {
+			return err
+		}
+		v.SetBool(b != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := decoder.varint()
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, err := decoder.uvarint()
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		if len(decoder.buf) < 8 {
+			return errShortSnapshot
+		}
+		v.SetFloat(math.Float64frombits(binary.LittleEndian.Uint64(decoder.buf)))
+		decoder.buf = decoder.buf[8:]
+	case reflect.String:
+		s, err := decoder.string()
+		if err != nil {
+			return err
+		}
+		v.SetString(s)
+	case reflect.Slice:
+		n, err := decoder.count()
+		if err != nil || n == 0 {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), n-1, n-1)
+		for i := range n - 1 {
+			if err := decoder.value(slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Array:
+		for i := range v.Len() {
+			if err := decoder.value(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		n, err := decoder.count()
+		if err != nil || n == 0 {
+			return err
+		}
+		m := reflect.MakeMapWithSize(v.Type(), n-1)
+		for range n - 1 {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := decoder.value(key); err != nil {
+				return err
+			}
+			value := reflect.New(v.Type().Elem()).Elem()
+			if err := decoder.value(value); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			if err := decoder.value(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Pointer:
+		b, err := decoder.byte()
+		if err != nil || b == 0 {
+			return err
+		}
+		elem := reflect.New(v.Type().Elem())
+		if err := decoder.value(elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+	default:
+		return fmt.Errorf("cannot decode %s", v.Type())
+	}
+	return nil
+}
+
+// cloneComponent deep copies component through the binary encoding, so the
+// copy shares no slices or maps with the original.
+func cloneComponent(component any) (any, error) {
+	encoder := &binaryEncoder{}
+	if err := encoder.value(reflect.ValueOf(component)); err != nil {
+		return nil, err
+	}
+	clone := reflect.New(reflect.TypeOf(component)).Elem()
+	if err := (&binaryDecoder{buf: encoder.buf}).value(clone); err != nil {
+		return nil, err
+	}
+	return clone.Interface(), nil
+}
@@ -0,0 +1,119 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+type VariationMode int
+
+const (
+	RoundRobin VariationMode = iota
+	Random
+)
+
+// SoundBank groups variations of one sound under a name that PlaySound
+// requests can use instead of a file path.
+type SoundBank struct {
+	Name   string
+	Sounds []string
+	Mode   VariationMode
+	Bus    string
+	Volume float64
+}
+
+type bankState struct {
+	bank SoundBank
+	next int
+}
+
+// PlaySound asks the AudioSystem to play a sound. Name is a sound bank or a
+// sound file. Variation picks a bank's sound by its 1-based index; zero lets
+// the bank choose. Zero Volume and Pitch mean 1, and an empty Bus means the
+// bank's bus, or SFXBus.
+type PlaySound struct {
+	Name      string
+	Bus       string
+	Volume    float64
+	Pitch     float64
+	Variation int
+}
+
+func (audio *Audio) AddBank(bank SoundBank) error {
+	if len(bank.Sounds) == 0 {
+		return fmt.Errorf("sound bank %q has no sounds", bank.Name)
+	}
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	audio.banks[bank.Name] = &bankState{bank: bank}
+	return nil
+}
+
+// Request plays a PlaySound request right away.
+func (audio *Audio) Request(request PlaySound) (*Voice, error) {
+	sound, bus, volume, err := audio.resolve(request)
+	if err != nil {
+		return nil, err
+	}
+	pitch := request.Pitch
+	if pitch == 0 {
+		pitch = 1
+	}
+	return audio.play(sound, bus, volume, pitch, false)
+}
+
+// StartRecording makes the mixer remember every request it receives until
+// Recorded is called, so tests can check which sounds gameplay asked for.
+func (audio *Audio) StartRecording() {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	audio.recording = true
+	audio.recorded = nil
+}
+
+// Recorded returns and clears the requests received since StartRecording or
+// the previous call.
+func (audio *Audio) Recorded() []PlaySound {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	recorded := audio.recorded
+	audio.recorded = nil
+	return recorded
+}
+
+func (audio *Audio) resolve(request PlaySound) (sound string, bus string, volume float64, err error) {
+	audio.mu.Lock()
+	defer audio.mu.Unlock()
+	if audio.recording {
+		audio.recorded = append(audio.recorded, request)
+	}
+	if request.Name == "" {
+		return "", "", 0, errors.New("sound request without a name")
+	}
+	sound, bus, volume = request.Name, request.Bus, request.Volume
+	if volume == 0 {
+		volume = 1
+	}
+	if state, ok := audio.banks[request.Name]; ok {
+		bank := state.bank
+		switch {
+		case request.Variation > 0:
+			sound = bank.Sounds[(request.Variation-1)%len(bank.Sounds)]
+		case bank.Mode == Random:
+			sound = bank.Sounds[audio.rand.IntN(len(bank.Sounds))]
+		default:
+			sound = bank.Sounds[state.next%len(bank.Sounds)]
+			state.next++
+		}
+		if bus == "" {
+			bus = bank.Bus
+		}
+		if bank.Volume != 0 {
+			volume *= bank.Volume
+		}
+	}
+	if bus == "" {
+		bus = SFXBus
+	}
+	return sound, bus, volume, nil
+}
@@ -0,0 +1,253 @@
+package engine
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+)
+
+// Point is a position in the plane of a SpatialHash.
+type Point struct {
+	X, Y float64
+}
+
+func (cell Cell) Point() Point {
+	return Point{X: float64(cell.X), Y: float64(cell.Y)}
+}
+
+func (point Point) Sub(other Point) Point {
+	return Point{X: point.X - other.X, Y: point.Y - other.Y}
+}
+
+func (point Point) Dot(other Point) float64 {
+	return point.X*other.X + point.Y*other.Y
+}
+
+func (point Point) Length() float64 {
+	return math.Hypot(point.X, point.Y)
+}
+
+// rayTolerance absorbs rounding when a ray passes exactly through a point.
+const rayTolerance = 1e-9
+
+type bucket struct {
+	x, y int
+}
+
+// SpatialHash indexes the entities with a position component by the bucket
+// of the plane they are in. Get it with Spatial.
+type SpatialHash struct {
+	cellSize float64
+	position reflect.Type
+	point    func(position any) Point
+	buckets  map[bucket][]uint32
+	points   map[uint32]Point
+}
+
+// RayHit is an entity found by SpatialHash.Ray, Distance along the ray from
+// its origin.
+type RayHit struct {
+	Entity   uint32
+	Distance float64
+}
+
+// IndexPositions makes the World keep a SpatialHash of the entities with a P,
+// using point to find where a P is. cellSize is the width of the buckets, 1
+// for grid positions.
+func IndexPositions[P any](world *World, cellSize float64, point func(P) Point) (*SpatialHash, error) {
+	if !(cellSize > 0) {
+		return nil, fmt.Errorf("cell size must be positive, got %v", cellSize)
+	}
+	hash := &SpatialHash{
+		cellSize: cellSize,
+		position: reflect.TypeFor[P](),
+		point: func(position any) Point {
+			return point(position.(P))
+		},
+	}
+	setHook(world, hash, func(other *SpatialHash) bool { return other.position == hash.position })
+	hash.reindex(world)
+	return hash, nil
+}
+
+// Spatial returns the SpatialHash of the entities with a P, if IndexPositions
+// was called for P.
+func Spatial[P any](world *World) (*SpatialHash, bool) {
+	return findHook(world, func(hash *SpatialHash) bool { return hash.position == reflect.TypeFor[P]() })
+}
+
+// Position returns where entity is in the index.
+func (hash *SpatialHash) Position(entity uint32) (Point, bool) {
+	point, ok := hash.points[entity]
+	return point, ok
+}
+
+// Len returns the number of entities in the index.
+func (hash *SpatialHash) Len() int {
+	return len(hash.points)
+}
+
+// At returns the entities exactly at point, sorted.
+func (hash *SpatialHash) At(point Point) []uint32 {
+	var entities []uint32
+	for _, entity := range hash.buckets[hash.bucket(point)] {
+		if hash.points[entity] == point {
+			entities = append(entities, entity)
+		}
+	}
+	slices.Sort(entities)
+	return entities
+}
+
+// Rect returns the entities from min to max, edges included, sorted.
+func (hash *SpatialHash) Rect(min, max Point) []uint32 {
+	var entities []uint32
+	hash.scan(min, max, func(entity uint32, point Point) {
+		if point.X >= min.X && point.Y >= min.Y && point.X <= max.X && point.Y <= max.Y {
+			entities = append(entities, entity)
+		}
+	})
+	slices.Sort(entities)
+	return entities
+}
+
+// Radius returns the entities at most radius from center, nearest first.
+func (hash *SpatialHash) Radius(center Point, radius float64) []uint32 {
+	var hits []RayHit
+	corner := Point{X: radius, Y: radius}
+	hash.scan(center.Sub(corner), Point{X: center.X + radius, Y: center.Y + radius}, func(entity uint32, point Point) {
+		if distance := point.Sub(center).Length(); distance <= radius {
+			hits = append(hits, RayHit{Entity: entity, Distance: distance})
+		}
+	})
+	sortHits(hits)
+	entities := make([]uint32, len(hits))
+	for i, hit := range hits {
+		entities[i] = hit.Entity
+	}
+	return entities
+}
+
+// Ray returns the entities at most width from the segment going length from
+// origin in direction, nearest to origin first.
+func (hash *SpatialHash) Ray(origin, direction Point, length, width float64) []RayHit {
+	norm := direction.Length()
+	if norm == 0 {
+		return nil
+	}
+	direction = Point{X: direction.X / norm, Y: direction.Y / norm}
+	end := Point{X: origin.X + direction.X*length, Y: origin.Y + direction.Y*length}
+	width += rayTolerance
+	var hits []RayHit
+	min := Point{X: math.Min(origin.X, end.X) - width, Y: math.Min(origin.Y, end.Y) - width}
+	max := Point{X: math.Max(origin.X, end.X) + width, Y: math.Max(origin.Y, end.Y) + width}
+	hash.scan(min, max, func(entity uint32, point Point) {
+		offset := point.Sub(origin)
+		along := offset.Dot(direction)
+		if along < -rayTolerance || along > length+rayTolerance {
+			return
+		}
+		across := offset.Sub(Point{X: direction.X * along, Y: direction.Y * along}).Length()
+		if across <= width {
+			hits = append(hits, RayHit{Entity: entity, Distance: math.Max(along, 0)})
+		}
+	})
+	sortHits(hits)
+	return hits
+}
+
+func sortHits(hits []RayHit) {
+	slices.SortFunc(hits, func(a, b RayHit) int {
+		return cmp.Or(cmp.Compare(a.Distance, b.Distance), cmp.Compare(a.Entity, b.Entity))
+	})
+}
+
+// scan calls visit for the entities in the buckets overlapping min to max.
+func (hash *SpatialHash) scan(min, max Point, visit func(entity uint32, point Point)) {
+	// The area is measured in float64 so that huge queries do not overflow.
+	fromX, fromY := math.Floor(min.X/hash.cellSize), math.Floor(min.Y/hash.cellSize)
+	toX, toY := math.Floor(max.X/hash.cellSize), math.Floor(max.Y/hash.cellSize)
+	if (toX-fromX+1)*(toY-fromY+1) > float64(len(hash.buckets)) {
+		// Fewer buckets are in use than the area covers.
+		for b, entities := range hash.buckets {
+			if x, y := float64(b.x), float64(b.y); x >= fromX && y >= fromY && x <= toX && y <= toY {
+				for _, entity := range entities {
+					visit(entity, hash.points[entity])
+				}
+			}
+		}
+		return
+	}
+	from, to := hash.bucket(min), hash.bucket(max)
+	for y := from.y; y <= to.y; y++ {
+		for x := from.x; x <= to.x; x++ {
+			for _, entity := range hash.buckets[bucket{x, y}] {
+				visit(entity, hash.points[entity])
+			}
+		}
+	}
+}
+
+func (hash *SpatialHash) bucket(point Point) bucket {
+	return bucket{
+		x: int(math.Floor(point.X / hash.cellSize)),
+		y: int(math.Floor(point.Y / hash.cellSize)),
+	}
+}
+
+func (hash *SpatialHash) insert(entity uint32, point Point) {
+	if previous, ok := hash.points[entity]; ok {
+		if previous == point {
+			return
+		}
+		hash.remove(entity)
+	}
+	hash.points[entity] = point
+	b := hash.bucket(point)
+	hash.buckets[b] = append(hash.buckets[b], entity)
+}
+
+func (hash *SpatialHash) remove(entity uint32) {
+	point, ok := hash.points[entity]
+	if !ok {
+		return
+	}
+	delete(hash.points, entity)
+	b := hash.bucket(point)
+	hash.buckets[b] = slices.DeleteFunc(hash.buckets[b], func(other uint32) bool { return other == entity })
+	if len(hash.buckets[b]) == 0 {
+		delete(hash.buckets, b)
+	}
+}
+
+func (hash *SpatialHash) reindex(world *World) {
+	hash.buckets = map[bucket][]uint32{}
+	hash.points = map[uint32]Point{}
+	for _, entity := range world.components.sortedEntities() {
+		if position, ok := world.GetEntityComponent(entity, hash.position); ok {
+			hash.insert(entity, hash.point(position))
+		}
+	}
+}
+
+// clone returns an empty SpatialHash indexing the same component.
+func (hash *SpatialHash) clone() worldHook {
+	return &SpatialHash{cellSize: hash.cellSize, position: hash.position, point: hash.point}
+}
+
+func (hash *SpatialHash) changed(world *World, entity uint32, t reflect.Type) {
+	if t != hash.position {
+		return
+	}
+	if position, ok := world.GetEntityComponent(entity, t); ok {
+		hash.insert(entity, hash.point(position))
+		return
+	}
+	hash.remove(entity)
+}
+
+func (hash *SpatialHash) deleted(_ *World, entity uint32) {
+	hash.remove(entity)
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gridPoint(position savedPosition) Point {
+	return Cell{X: position.X, Y: position.Y}.Point()
+}
+
+func TestSpatialHashFollowsPositions(t *testing.T) {
+	world := newWorld()
+	a := world.CreateEntity(savedPosition{X: 1, Y: 1})
+	hash, err := IndexPositions(world, 4, gridPoint)
+	require.NoError(t, err)
+	b := world.CreateEntity(savedPosition{X: 1, Y: 1})
+	c := world.CreateEntity()
+	world.AddComponent(c, savedPosition{X: 5, Y: -2})
+
+	assert.Equal(t, []uint32{a, b}, hash.At(Point{1, 1}), "entities from before IndexPositions are indexed")
+	assert.Equal(t, []uint32{c}, hash.At(Point{5, -2}))
+
+	world.ReplaceComponent(a, savedPosition{X: 9, Y: 9})
+	assert.Equal(t, []uint32{b}, hash.At(Point{1, 1}))
+	world.RemoveComponent(b, reflect.TypeOf(savedPosition{}))
+	assert.Empty(t, hash.At(Point{1, 1}))
+	world.DeleteEntity(c)
+	assert.Empty(t, hash.At(Point{5, -2}))
+	assert.Equal(t, 1, hash.Len())
+
+	found, ok := Spatial[savedPosition](world)
+	assert.True(t, ok)
+	assert.Same(t, hash, found)
+	_, ok = Spatial[savedTag](world)
+	assert.False(t, ok)
+
+	_, err = IndexPositions(world, 0, gridPoint)
+	assert.Error(t, err)
+	found, _ = Spatial[savedPosition](world)
+	assert.Same(t, hash, found, "the index is kept")
+}
+
+func TestSpatialHashQueries(t *testing.T) {
+	world := newWorld()
+	hash, err := IndexPositions(world, 2, gridPoint)
+	require.NoError(t, err)
+	var row []uint32
+	for x := range 5 {
+		row = append(row, world.CreateEntity(savedPosition{X: x, Y: 0}))
+	}
+	below := world.CreateEntity(savedPosition{X: 2, Y: 2})
+	diagonal := world.CreateEntity(savedPosition{X: -3, Y: -3})
+
+	assert.Equal(t, row[1:4], hash.Rect(Point{1, 0}, Point{3, 1}))
+	assert.Len(t, hash.Rect(Point{-1e18, -1e18}, Point{1e18, 1e18}), 7, "huge areas do not overflow")
+	assert.Equal(t, []uint32{row[2], row[1], row[3], row[0], row[4], below}, hash.Radius(Point{2, 0}, 2))
+
+	hits := hash.Ray(Point{1, 0}, Point{1, 0}, 2, 0)
+	assert.Equal(t, []RayHit{{row[1], 0}, {row[2], 1}, {row[3], 2}}, hits)
+	hits = hash.Ray(Point{0, 0}, Point{-1, -1}, 10, 0)
+	require.Len(t, hits, 2)
+	assert.Equal(t, diagonal, hits[1].Entity)
+	assert.InDelta(t, 4.243, hits[1].Distance, 0.001)
+	assert.Len(t, hash.Ray(Point{0, 1}, Point{1, 0}, 4, 1), 6, "width reaches the rows on both sides of the ray")
+	assert.Empty(t, hash.Ray(Point{0, 0}, Point{}, 4, 1))
+}
+
+func TestSpatialHashAfterUndoCloneAndScenes(t *testing.T) {
+	world := newWorld()
+	hash, err := IndexPositions(world, 1, gridPoint)
+	require.NoError(t, err)
+	world.PushScene(&testScene{system: &countingSystem{}})
+	require.NoError(t, world.applySceneTransitions())
+	entity := world.CreateEntity(savedPosition{})
+
+	world.History().BeginTurn()
+	world.ReplaceComponent(entity, savedPosition{X: 3})
+	world.History().Undo()
+	assert.Equal(t, []uint32{entity}, hash.At(Point{}))
+
+	clone := world.Clone()
+	clone.ReplaceComponent(entity, savedPosition{X: 1})
+	assert.Equal(t, []uint32{entity}, hash.At(Point{}), "the clone has its own index")
+	cloned, _ := Spatial[savedPosition](clone)
+	assert.Equal(t, []uint32{entity}, cloned.At(Point{1, 0}))
+
+	world.PushScene(&testScene{system: &countingSystem{}})
+	require.NoError(t, world.applySceneTransitions())
+	assert.Zero(t, hash.Len(), "the covered scene's entities are not indexed")
+	world.PopScene()
+	require.NoError(t, world.applySceneTransitions())
+	assert.Equal(t, []uint32{entity}, hash.At(Point{}))
+}
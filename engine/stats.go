@@ -0,0 +1,146 @@
+package engine
+
+import (
+	"reflect"
+	"slices"
+	"time"
+)
+
+// statsWindow is the number of frames the rolling statistics cover.
+const statsWindow = 120
+
+// Timings summarize how long something took over the last frames.
+type Timings struct {
+	Last    time.Duration
+	Average time.Duration
+	P95     time.Duration
+	Max     time.Duration
+}
+
+type SystemStats struct {
+	// Name is the system's type, e.g. "*main.MoveSystem".
+	Name       string
+	Initialize time.Duration
+	Update     Timings
+}
+
+// Churn counts the changes made through the World during one frame.
+// GroupChanges counts entities entering or leaving groups.
+type Churn struct {
+	EntitiesCreated    int
+	EntitiesDeleted    int
+	ComponentsAdded    int
+	ComponentsReplaced int
+	ComponentsRemoved  int
+	GroupChanges       int
+}
+
+type Stats struct {
+	Frames   uint64
+	Frame    Timings
+	Systems  []SystemStats
+	Churn    Churn
+	Entities int
+	Groups   int
+}
+
+type profiler struct {
+	systems   map[System]*systemProfile
+	frame     timingWindow
+	frames    uint64
+	churn     Churn
+	lastChurn Churn
+}
+
+type systemProfile struct {
+	initialize time.Duration
+	update     timingWindow
+}
+
+type timingWindow struct {
+	samples [statsWindow]time.Duration
+	n       int
+	next    int
+}
+
+func newProfiler() *profiler {
+	return &profiler{systems: map[System]*systemProfile{}}
+}
+
+func (window *timingWindow) add(sample time.Duration) {
+	window.samples[window.next] = sample
+	window.next = (window.next + 1) % statsWindow
+	window.n = min(window.n+1, statsWindow)
+}
+
+func (window *timingWindow) timings() Timings {
+	if window.n == 0 {
+		return Timings{}
+	}
+	samples := slices.Clone(window.samples[:window.n])
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+	last := window.samples[(window.next+statsWindow-1)%statsWindow]
+	slices.Sort(samples)
+	return Timings{
+		Last:    last,
+		Average: total / time.Duration(len(samples)),
+		P95:     samples[(len(samples)*95+99)/100-1],
+		Max:     samples[len(samples)-1],
+	}
+}
+
+func (p *profiler) system(system System) *systemProfile {
+	profile, ok := p.systems[system]
+	if !ok {
+		profile = &systemProfile{}
+		p.systems[system] = profile
+	}
+	return profile
+}
+
+// endFrame records the frame's duration and starts counting churn anew.
+func (p *profiler) endFrame(duration time.Duration, groups map[Matcher]*Group) {
+	for _, group := range groups {
+		p.churn.GroupChanges += int(group.changes.Swap(0))
+	}
+	p.frame.add(duration)
+	p.frames++
+	p.lastChurn = p.churn
+	p.churn = Churn{}
+}
+
+// Stats returns timings of the frames and the current systems over the last
+// statsWindow frames, and the churn of the last frame.
+func (world *World) Stats() Stats {
+	stats := Stats{
+		Frames:   world.profiler.frames,
+		Frame:    world.profiler.frame.timings(),
+		Churn:    world.profiler.lastChurn,
+		Entities: int(world.components.entities.Len()),
+		Groups:   len(world.groups),
+	}
+	for _, system := range world.statsSystems() {
+		profile := world.profiler.system(system)
+		stats.Systems = append(stats.Systems, SystemStats{
+			Name:       reflect.TypeOf(system).String(),
+			Initialize: profile.initialize,
+			Update:     profile.update.timings(),
+		})
+	}
+	return stats
+}
+
+// statsSystems returns the update systems in order, then the systems that
+// only initialize.
+func (world *World) statsSystems() []System {
+	systems := slices.Clone(world.systems[Update])
+	for _, system := range world.systems[Initialize] {
+		if !slices.Contains(systems, system) {
+			systems = append(systems, system)
+		}
+	}
+	return systems
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sleepySystem struct {
+	frame int
+}
+
+func (system *sleepySystem) Update(world *World) error {
+	system.frame++
+	if system.frame%10 == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return nil
+}
+
+func TestStats(t *testing.T) {
+	world := newWorld()
+	sleepy := &sleepySystem{}
+	walk := &walkSystem{inputs: []savedPosition{{X: 1}}}
+	world.AddSystems(sleepy, walk)
+	world.CreateEntity(savedPosition{})
+	world.initialize()
+	for range 21 {
+		world.update()
+	}
+
+	stats := world.Stats()
+	assert.Equal(t, uint64(21), stats.Frames)
+	assert.Len(t, stats.Systems, 2)
+	assert.Equal(t, "*engine.sleepySystem", stats.Systems[0].Name)
+	assert.GreaterOrEqual(t, stats.Systems[0].Update.Max, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, stats.Systems[0].Update.P95, 5*time.Millisecond)
+	assert.Less(t, stats.Systems[0].Update.Average, 5*time.Millisecond)
+	assert.GreaterOrEqual(t, stats.Frame.Max, stats.Systems[0].Update.Max)
+
+	// The last frame moved every entity and then deleted one.
+	assert.Equal(t, Churn{EntitiesDeleted: 1, ComponentsReplaced: stats.Entities + 1, GroupChanges: 1}, stats.Churn)
+}
+
+func TestTimingWindow(t *testing.T) {
+	var window timingWindow
+	for i := 1; i <= statsWindow+20; i++ {
+		window.add(time.Duration(i))
+	}
+	timings := window.timings()
+	assert.Equal(t, time.Duration(statsWindow+20), timings.Last)
+	assert.Equal(t, time.Duration(statsWindow+20), timings.Max)
+	assert.Equal(t, time.Duration(20+statsWindow*95/100), timings.P95)
+	assert.Equal(t, time.Duration(20+(statsWindow+1)/2), timings.Average)
+}
@@ -0,0 +1,97 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+)
+
+// StatsOverlay is the resource that decides whether the StatsOverlaySystem
+// draws. It outlives the scenes, so the overlay stays up between levels.
+type StatsOverlay struct {
+	Visible bool
+}
+
+// StatsOverlaySystem draws World.Stats in the top right corner of the
+// window. Pressing Key shows or hides it. Add it last so it draws over the
+// frame.
+type StatsOverlaySystem struct {
+	Font string
+	Size int
+	Key  sdl.Keycode
+	font *Asset[*ttf.Font]
+}
+
+func (s *StatsOverlaySystem) Close() error {
+	s.font.Release()
+	return nil
+}
+
+func (s *StatsOverlaySystem) Initialize(world *World) error {
+	font, err := world.Assets.Font(s.Font, s.Size)
+	if err != nil {
+		return err
+	}
+	s.font = font
+	if !HasResource[StatsOverlay](world) {
+		InsertResource(world, StatsOverlay{})
+	}
+	return nil
+}
+
+func (s *StatsOverlaySystem) Update(world *World) error {
+	overlay, err := ResourceMut[StatsOverlay](world)
+	if err != nil {
+		return err
+	}
+	input, err := Resource[Input](world)
+	if err != nil {
+		return err
+	}
+	if input.KeyPressed(s.Key) {
+		overlay.Visible = !overlay.Visible
+	}
+	if !overlay.Visible || world.Window == nil {
+		return nil
+	}
+
+	surface, err := world.Window.GetSurface()
+	if err != nil {
+		return err
+	}
+	font := s.font.Get()
+	y := int32(0)
+	for _, line := range statsLines(world.Stats()) {
+		text, err := font.RenderUTF8Blended(line, sdl.Color{R: 255, G: 255, B: 0, A: 255})
+		if err != nil {
+			return err
+		}
+		background := &sdl.Rect{X: surface.W - text.W, Y: y, W: text.W, H: text.H}
+		_ = surface.FillRect(background, 0)
+		_ = text.Blit(nil, surface, background)
+		y += text.H
+		text.Free()
+	}
+	return nil
+}
+
+func statsLines(stats Stats) []string {
+	lines := []string{
+		fmt.Sprintf("frame %s avg %s p95 %s max", millis(stats.Frame.Average), millis(stats.Frame.P95), millis(stats.Frame.Max)),
+		fmt.Sprintf("%d entities  %d groups", stats.Entities, stats.Groups),
+		fmt.Sprintf("+%d -%d entities  +%d ~%d -%d components  %d group changes",
+			stats.Churn.EntitiesCreated, stats.Churn.EntitiesDeleted,
+			stats.Churn.ComponentsAdded, stats.Churn.ComponentsReplaced, stats.Churn.ComponentsRemoved,
+			stats.Churn.GroupChanges),
+	}
+	for _, system := range stats.Systems {
+		lines = append(lines, fmt.Sprintf("%s %s avg %s p95 %s max", system.Name, millis(system.Update.Average), millis(system.Update.P95), millis(system.Update.Max)))
+	}
+	return lines
+}
+
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", float64(d)/float64(time.Millisecond))
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"io"
+	"reflect"
+	"slices"
+)
+
+// SetSystemEnabled stops or resumes running system's Update. Enabling a system
+// also clears the failures that disabled it.
+func (world *World) SetSystemEnabled(system System, enabled bool) {
+	if enabled {
+		delete(world.disabled, system)
+		delete(world.failures, system)
+		return
+	}
+	world.disabled[system] = true
+}
+
+// SystemEnabled reports whether system is added and neither disabled nor
+// failed.
+func (world *World) SystemEnabled(system System) bool {
+	return world.hasSystem(system) && !world.disabled[system]
+}
+
+// RunIf makes system's Update run only in frames where condition holds. A
+// system with several conditions runs when all of them hold.
+func (world *World) RunIf(system System, condition func(world *World) bool) *World {
+	world.conditions[system] = append(world.conditions[system], condition)
+	return world
+}
+
+// InScene is a RunIf condition that holds while a scene of type S is on top.
+func InScene[S Scene](world *World) bool {
+	_, ok := world.scene.(S)
+	return ok
+}
+
+// RemoveSystem removes system from the world and closes it if it is an
+// io.Closer. A system removed during a frame does not run for the rest of it.
+func (world *World) RemoveSystem(system System) {
+	if !world.hasSystem(system) {
+		return
+	}
+	for phase, systems := range world.systems {
+		// Update may be ranging over the old slice.
+		world.systems[phase] = slices.DeleteFunc(slices.Clone(systems), func(s System) bool {
+			return s == system
+		})
+	}
+	world.removed[system] = true
+	world.closeSystem(system)
+}
+
+func (world *World) hasSystem(system System) bool {
+	return slices.Contains(world.systems[Initialize], system) || slices.Contains(world.systems[Update], system)
+}
+
+// shouldRun reports whether system's Update runs this frame.
+func (world *World) shouldRun(system System) bool {
+	if world.disabled[system] || world.removed[system] {
+		return false
+	}
+	for _, condition := range world.conditions[system] {
+		if !condition(world) {
+			return false
+		}
+	}
+	return true
+}
+
+func (world *World) closeSystem(system System) {
+	delete(world.profiler.systems, system)
+	delete(world.failures, system)
+	delete(world.disabled, system)
+	delete(world.conditions, system)
+	if closer, ok := system.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			world.Logger.Error("Failed closing system", "system", reflect.TypeOf(system).String(), "error", err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type controlledSystem struct {
+	updates int
+	closed  bool
+	remove  System
+}
+
+func (system *controlledSystem) Update(world *World) error {
+	system.updates++
+	if system.remove != nil {
+		world.RemoveSystem(system.remove)
+	}
+	return nil
+}
+
+func (system *controlledSystem) Close() error {
+	system.closed = true
+	return nil
+}
+
+type pausedResource struct {
+	Paused bool
+}
+
+func TestSetSystemEnabled(t *testing.T) {
+	world := newWorld()
+	system := &controlledSystem{}
+	world.AddSystems(system)
+
+	world.SetSystemEnabled(system, false)
+	world.update()
+	assert.False(t, world.SystemEnabled(system))
+	world.SetSystemEnabled(system, true)
+	world.update()
+	assert.True(t, world.SystemEnabled(system))
+	assert.Equal(t, 1, system.updates)
+}
+
+func TestRunIf(t *testing.T) {
+	world := newWorld()
+	InsertResource(world, pausedResource{})
+	system := &controlledSystem{}
+	world.AddSystems(system).RunIf(system, func(world *World) bool {
+		paused, _ := Resource[pausedResource](world)
+		return !paused.Paused
+	})
+
+	world.update()
+	InsertResource(world, pausedResource{Paused: true})
+	world.update()
+	assert.Equal(t, 1, system.updates)
+
+	other := &controlledSystem{}
+	world.AddSystems(other).RunIf(other, InScene[*testScene])
+	world.update()
+	assert.Equal(t, 0, other.updates)
+}
+
+func TestRemoveSystem(t *testing.T) {
+	world := newWorld()
+	removed := &controlledSystem{}
+	remover := &controlledSystem{remove: removed}
+	world.AddSystems(remover, removed)
+
+	world.update()
+	world.update()
+	assert.Equal(t, 2, remover.updates)
+	assert.Equal(t, 0, removed.updates, "removed before its turn in the frame")
+	assert.True(t, removed.closed)
+	assert.False(t, remover.closed)
+	assert.Len(t, world.Stats().Systems, 1)
+}
@@ -0,0 +1,268 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+var (
+	ErrOutOfBounds  = errors.New("cell is outside the tilemap")
+	ErrCellOccupied = errors.New("cell is occupied")
+)
+
+func init() {
+	RegisterComponent[TileLayer]("engine.TileLayer")
+	RegisterResource[Tilemap]("engine.Tilemap")
+}
+
+type Cell struct {
+	X, Y int
+}
+
+func (cell Cell) Add(offset Cell) Cell {
+	return Cell{X: cell.X + offset.X, Y: cell.Y + offset.Y}
+}
+
+// TileLayer is the component naming the Tilemap layer an entity is in.
+type TileLayer string
+
+// Tilemap is the resource indexing which entity is in which cell, at most
+// one per layer. TrackTiles keeps it up to date as positions change.
+type Tilemap struct {
+	Width, Height int
+	Layers        []string
+	layers        map[string][]uint32
+	cells         map[uint32]tilePlace
+}
+
+type tilePlace struct {
+	layer string
+	cell  Cell
+}
+
+const emptyTile = ^uint32(0)
+
+var tileLayerType = reflect.TypeFor[TileLayer]()
+
+func NewTilemap(width, height int, layers ...string) Tilemap {
+	return Tilemap{Width: width, Height: height, Layers: layers}
+}
+
+func (tilemap *Tilemap) InBounds(cell Cell) bool {
+	return cell.X >= 0 && cell.Y >= 0 && cell.X < tilemap.Width && cell.Y < tilemap.Height
+}
+
+// At returns the entity in cell on layer, if there is one.
+func (tilemap *Tilemap) At(layer string, cell Cell) (uint32, bool) {
+	cells, ok := tilemap.layers[layer]
+	if !ok || !tilemap.InBounds(cell) {
+		return 0, false
+	}
+	entity := cells[tilemap.index(cell)]
+	return entity, entity != emptyTile
+}
+
+// Cell returns the cell entity is in.
+func (tilemap *Tilemap) Cell(entity uint32) (Cell, bool) {
+	place, ok := tilemap.cells[entity]
+	return place.cell, ok
+}
+
+// Neighbors returns the cells next to cell that are on the map, in the order
+// up, right, down, left, followed by the diagonal ones if diagonal is set.
+func (tilemap *Tilemap) Neighbors(cell Cell, diagonal bool) []Cell {
+	offsets := []Cell{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	if diagonal {
+		offsets = append(offsets, Cell{1, -1}, Cell{1, 1}, Cell{-1, 1}, Cell{-1, -1})
+	}
+	var neighbors []Cell
+	for _, offset := range offsets {
+		if neighbor := cell.Add(offset); tilemap.InBounds(neighbor) {
+			neighbors = append(neighbors, neighbor)
+		}
+	}
+	return neighbors
+}
+
+func (tilemap *Tilemap) index(cell Cell) int {
+	return cell.Y*tilemap.Width + cell.X
+}
+
+func (tilemap *Tilemap) clear() {
+	tilemap.layers = make(map[string][]uint32, len(tilemap.Layers))
+	for _, layer := range tilemap.Layers {
+		cells := make([]uint32, tilemap.Width*tilemap.Height)
+		for i := range cells {
+			cells[i] = emptyTile
+		}
+		tilemap.layers[layer] = cells
+	}
+	tilemap.cells = map[uint32]tilePlace{}
+}
+
+func (tilemap *Tilemap) remove(entity uint32) {
+	place, ok := tilemap.cells[entity]
+	if !ok {
+		return
+	}
+	delete(tilemap.cells, entity)
+	cells := tilemap.layers[place.layer]
+	if i := tilemap.index(place.cell); cells[i] == entity {
+		cells[i] = emptyTile
+	}
+}
+
+// place puts entity in cell. It fails if another entity is there already.
+func (tilemap *Tilemap) place(entity uint32, layer string, cell Cell) error {
+	cells, ok := tilemap.layers[layer]
+	if !ok {
+		return fmt.Errorf("unknown tile layer %q", layer)
+	}
+	if !tilemap.InBounds(cell) {
+		return fmt.Errorf("%w: %d %d", ErrOutOfBounds, cell.X, cell.Y)
+	}
+	i := tilemap.index(cell)
+	if cells[i] != emptyTile && cells[i] != entity {
+		return fmt.Errorf("%w: %d %d", ErrCellOccupied, cell.X, cell.Y)
+	}
+	tilemap.remove(entity)
+	cells[i] = entity
+	tilemap.cells[entity] = tilePlace{layer: layer, cell: cell}
+	return nil
+}
+
+// OccupancyRule reports why entity may not enter cell, or nil if it may.
+type OccupancyRule func(world *World, entity uint32, cell Cell) error
+
+type tileTracker struct {
+	position reflect.Type
+	cell     func(position any) Cell
+	place    func(position any, cell Cell) any
+	rules    []OccupancyRule
+}
+
+// TrackTiles makes the Tilemap resource follow the entities with a P and a
+// TileLayer. cell finds the cell of a P and place moves a P to a cell.
+func TrackTiles[P any](world *World, cell func(P) Cell, place func(P, Cell) P) {
+	tiles := &tileTracker{
+		position: reflect.TypeFor[P](),
+		cell: func(position any) Cell {
+			return cell(position.(P))
+		},
+		place: func(position any, to Cell) any {
+			return place(position.(P), to)
+		},
+	}
+	setHook(world, tiles, func(*tileTracker) bool { return true })
+	tiles.reindex(world)
+}
+
+// tiles returns the tracker TrackTiles set up, or nil.
+func (world *World) tiles() *tileTracker {
+	tiles, _ := findHook(world, func(*tileTracker) bool { return true })
+	return tiles
+}
+
+// AddOccupancyRule adds a rule CanOccupy checks after its own: that the cell
+// is on the map and free in the entity's layer. Call it after TrackTiles.
+func (world *World) AddOccupancyRule(rule OccupancyRule) {
+	tiles := world.tiles()
+	tiles.rules = append(tiles.rules, rule)
+}
+
+// CanOccupy returns why entity may not move into cell, or nil if it may.
+func (world *World) CanOccupy(entity uint32, cell Cell) error {
+	tilemap, err := ResourceMut[Tilemap](world)
+	if err != nil {
+		return err
+	}
+	if !tilemap.InBounds(cell) {
+		return fmt.Errorf("%w: %d %d", ErrOutOfBounds, cell.X, cell.Y)
+	}
+	if other, ok := world.occupant(tilemap, entity, cell); ok {
+		return fmt.Errorf("%w by %s", ErrCellOccupied, world.components.names.ref(other))
+	}
+	return world.checkRules(entity, cell)
+}
+
+// occupant returns the other entity in cell in entity's layer.
+func (world *World) occupant(tilemap *Tilemap, entity uint32, cell Cell) (uint32, bool) {
+	layer, ok := world.GetEntityComponent(entity, tileLayerType)
+	if !ok {
+		return 0, false
+	}
+	other, ok := tilemap.At(string(layer.(TileLayer)), cell)
+	return other, ok && other != entity
+}
+
+func (world *World) checkRules(entity uint32, cell Cell) error {
+	tiles := world.tiles()
+	if tiles == nil {
+		return nil
+	}
+	for _, rule := range tiles.rules {
+		if err := rule(world, entity, cell); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changed updates the Tilemap after a component of type t of entity changed.
+func (tiles *tileTracker) changed(world *World, entity uint32, t reflect.Type) {
+	if t != tiles.position && t != tileLayerType {
+		return
+	}
+	tilemap, err := ResourceMut[Tilemap](world)
+	if err != nil {
+		return
+	}
+	if tilemap.layers == nil {
+		tiles.reindex(world)
+		return
+	}
+	tiles.placeTile(world, tilemap, entity)
+}
+
+func (tiles *tileTracker) placeTile(world *World, tilemap *Tilemap, entity uint32) {
+	position, hasPosition := world.GetEntityComponent(entity, tiles.position)
+	layer, hasLayer := world.GetEntityComponent(entity, tileLayerType)
+	if !hasPosition || !hasLayer {
+		tilemap.remove(entity)
+		return
+	}
+	cell := tiles.cell(position)
+	if err := tilemap.place(entity, string(layer.(TileLayer)), cell); err != nil {
+		tilemap.remove(entity)
+		logger := world.EntityLogger(entity)
+		if other, ok := tilemap.At(string(layer.(TileLayer)), cell); ok {
+			logger = logger.With("other", world.components.names.ref(other))
+		}
+		logger.Error("Failed placing entity on tilemap", "error", err)
+	}
+}
+
+func (tiles *tileTracker) deleted(world *World, entity uint32) {
+	if tilemap, err := ResourceMut[Tilemap](world); err == nil {
+		tilemap.remove(entity)
+	}
+}
+
+func (tiles *tileTracker) reindex(world *World) {
+	tilemap, err := ResourceMut[Tilemap](world)
+	if err != nil {
+		return
+	}
+	tilemap.clear()
+	for _, entity := range world.components.sortedEntities() {
+		tiles.placeTile(world, tilemap, entity)
+	}
+}
+
+func (tiles *tileTracker) clone() worldHook {
+	clone := *tiles
+	clone.rules = slices.Clone(tiles.rules)
+	return &clone
+}
@@ -0,0 +1,118 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWall = errors.New("wall")
+
+func newTilemapWorld() *World {
+	world := newWorld()
+	InsertResource(world, NewTilemap(4, 3, "floor", "actors"))
+	TrackTiles(world, func(position savedPosition) Cell {
+		return Cell{X: position.X, Y: position.Y}
+	}, func(position savedPosition, cell Cell) savedPosition {
+		return savedPosition{X: cell.X, Y: cell.Y}
+	})
+	return world
+}
+
+func TestTilemapFollowsPositions(t *testing.T) {
+	world := newTilemapWorld()
+	floor := world.CreateEntity(savedPosition{X: 1, Y: 1}, TileLayer("floor"))
+	player := world.CreateEntity(savedPosition{X: 1, Y: 1}, TileLayer("actors"))
+	world.CreateEntity(savedPosition{X: 2, Y: 1})
+
+	tilemap, _ := ResourceMut[Tilemap](world)
+	entity, ok := tilemap.At("floor", Cell{1, 1})
+	assert.True(t, ok)
+	assert.Equal(t, floor, entity)
+	_, ok = tilemap.At("actors", Cell{2, 1})
+	assert.False(t, ok, "entities without a layer are not on the map")
+	_, ok = tilemap.At("actors", Cell{-1, 7})
+	assert.False(t, ok)
+
+	world.ReplaceComponent(player, savedPosition{X: 3, Y: 2})
+	_, ok = tilemap.At("actors", Cell{1, 1})
+	assert.False(t, ok)
+	cell, _ := tilemap.Cell(player)
+	assert.Equal(t, Cell{3, 2}, cell)
+
+	world.DeleteEntity(player)
+	_, ok = tilemap.At("actors", Cell{3, 2})
+	assert.False(t, ok)
+
+	assert.Equal(t, []Cell{{3, 1}, {2, 0}}, tilemap.Neighbors(Cell{3, 0}, false))
+	assert.Len(t, tilemap.Neighbors(Cell{0, 0}, true), 3)
+}
+
+func TestCanOccupy(t *testing.T) {
+	world := newTilemapWorld()
+	world.CreateEntity(savedPosition{X: 0, Y: 0}, TileLayer("floor"), savedTag{})
+	player := world.CreateEntity(Name("Player"), savedPosition{X: 1, Y: 0}, TileLayer("actors"))
+	world.CreateEntity(savedPosition{X: 2, Y: 0}, TileLayer("actors"))
+	world.AddOccupancyRule(func(world *World, entity uint32, cell Cell) error {
+		tilemap, _ := ResourceMut[Tilemap](world)
+		if floor, ok := tilemap.At("floor", cell); ok && world.HasComponent(floor, reflect.TypeOf(savedTag{})) {
+			return errWall
+		}
+		return nil
+	})
+
+	assert.NoError(t, world.CanOccupy(player, Cell{1, 1}))
+	assert.NoError(t, world.CanOccupy(player, Cell{1, 0}), "an entity does not block itself")
+	assert.ErrorIs(t, world.CanOccupy(player, Cell{2, 0}), ErrCellOccupied)
+	assert.ErrorIs(t, world.CanOccupy(player, Cell{4, 0}), ErrOutOfBounds)
+	assert.ErrorIs(t, world.CanOccupy(player, Cell{0, 0}), errWall)
+}
+
+func TestTilemapAfterUndoAndRestore(t *testing.T) {
+	world := newTilemapWorld()
+	player := world.CreateEntity(savedPosition{}, TileLayer("actors"))
+	snapshot, err := world.Snapshot()
+	require.NoError(t, err)
+
+	world.History().BeginTurn()
+	world.ReplaceComponent(player, savedPosition{X: 2})
+	world.History().Undo()
+	tilemap, _ := ResourceMut[Tilemap](world)
+	cell, _ := tilemap.Cell(player)
+	assert.Equal(t, Cell{0, 0}, cell)
+
+	world.ReplaceComponent(player, savedPosition{X: 3})
+	require.NoError(t, world.Restore(snapshot))
+	tilemap, _ = ResourceMut[Tilemap](world)
+	entity, ok := tilemap.At("actors", Cell{0, 0})
+	assert.True(t, ok)
+	assert.Equal(t, player, entity)
+
+	clone := world.Clone()
+	clone.ReplaceComponent(player, savedPosition{X: 1})
+	_, ok = tilemap.At("actors", Cell{0, 0})
+	assert.True(t, ok, "the clone has its own index")
+	clone.AddOccupancyRule(func(*World, uint32, Cell) error { return errWall })
+	assert.NoError(t, world.CanOccupy(player, Cell{1, 1}), "and its own rules")
+}
+
+func TestTilemapKeepsTheFirstEntityInACell(t *testing.T) {
+	world := newTilemapWorld()
+	first := world.CreateEntity(savedPosition{X: 1}, TileLayer("actors"))
+	second := world.CreateEntity(savedPosition{X: 1}, TileLayer("actors"))
+
+	tilemap, _ := ResourceMut[Tilemap](world)
+	entity, _ := tilemap.At("actors", Cell{1, 0})
+	assert.Equal(t, first, entity)
+	_, ok := tilemap.Cell(second)
+	assert.False(t, ok)
+	cell, _ := tilemap.Cell(first)
+	assert.Equal(t, Cell{1, 0}, cell)
+
+	world.ReplaceComponent(second, savedPosition{X: 2})
+	cell, _ = tilemap.Cell(second)
+	assert.Equal(t, Cell{2, 0}, cell)
+}
@@ -9,15 +9,17 @@ type Time struct {
 	DeltaTime       time.Duration
 	Timestep        time.Duration
 	PhysicsTimestep time.Duration
+	// Scale speeds up or slows down DeltaTime, e.g. 0.5 for half speed.
+	Scale float64
 }
 
 func newTime(timestep time.Duration, physicsTimestep time.Duration) *Time {
 	//return &Time{Current: time.Now().UTC(), Timestep: time.Second / 60}
-	return &Time{Current: time.Now().UTC(), Timestep: timestep, PhysicsTimestep: physicsTimestep}
+	return &Time{Current: time.Now().UTC(), Timestep: timestep, PhysicsTimestep: physicsTimestep, Scale: 1}
 }
 
 func (t *Time) update() {
 	now := time.Now().UTC()
-	t.DeltaTime = now.Sub(t.Current)
+	t.DeltaTime = time.Duration(float64(now.Sub(t.Current)) * t.Scale)
 	t.Current = now
 }
@@ -1,12 +1,13 @@
 package engine
 
 import (
-	"fmt"
-	"io"
+	"errors"
+	"io/fs"
 	"log/slog"
+	"os"
 	"reflect"
-	"runtime"
 	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 
@@ -17,12 +18,37 @@ import (
 var lock = &sync.Mutex{}
 
 type World struct {
-	systems    map[SystemType][]System
-	components *ComponentStorage
-	groups     map[Matcher]*Group
-	running    bool
-	Window     *sdl.Window
-	Time       *Time
+	systems     map[SystemType][]System
+	components  *ComponentStorage
+	groups      map[Matcher]*Group
+	running     bool
+	scene       Scene
+	scenes      []*sceneFrame
+	transitions []sceneTransition
+	history     *History
+	resources   map[reflect.Type]any
+	hooks       []worldHook
+	events      map[reflect.Type]eventQueue
+	profiler    *profiler
+	failures    map[System]int
+	disabled    map[System]bool
+	conditions  map[System][]func(world *World) bool
+	removed     map[System]bool
+	stopErrors  []error
+	inspector   *Inspector
+	logContext  *logContext
+	Window      *sdl.Window
+	RNG         *RNG
+	Assets      *AssetManager
+	Prefabs     *Prefabs
+	Commands    *Commands
+	// Logger is what the engine logs through. Replace it with
+	// ConfigureLogging.
+	Logger *slog.Logger
+	// FailurePolicy decides what happens when a system fails, unless the
+	// system implements FailurePolicySystem.
+	FailurePolicy FailurePolicy
+	Audio         *Audio
 }
 
 var worldInstance *World
@@ -39,13 +65,27 @@ func GetInstance() *World {
 }
 
 func newWorld() *World {
-	return &World{
+	world := &World{
 		systems:    map[SystemType][]System{},
-		Time:       newTime(time.Second/60, time.Second/60),
+		RNG:        NewRNG(newSeed()),
+		Assets:     NewAssetManager(os.DirFS(".")),
+		Prefabs:    NewPrefabs(),
+		Commands:   NewCommands(),
+		profiler:   newProfiler(),
 		components: NewComponentStorage(),
 		groups:     make(map[Matcher]*Group),
+		resources:  map[reflect.Type]any{},
+		failures:   map[System]int{},
+		disabled:   map[System]bool{},
+		conditions: map[System][]func(world *World) bool{},
+		removed:    map[System]bool{},
+		logContext: &logContext{},
 		running:    true,
 	}
+	world.Logger = world.newLogger(slog.Default().Handler(), time.Second)
+	InsertResource(world, *newTime(time.Second/60, time.Second/60))
+	InsertResource(world, Input{KeyState: make(map[sdl.Keycode]bool)})
+	return world
 }
 
 func (world *World) InitWindow(name string, width, height int32) {
@@ -63,10 +103,31 @@ func (world *World) InitWindow(name string, width, height int32) {
 	if err != nil {
 		panic(err)
 	}
-	print("Window created")
+	world.Logger.Debug("Window created", "name", name, "width", width, "height", height)
 	world.Window = window
 }
 
+// UseAssets makes the world load its assets from fsys, freeing everything
+// loaded from the previous file system.
+func (world *World) UseAssets(fsys fs.FS) *World {
+	if err := world.Assets.Close(); err != nil {
+		world.Logger.Error("Failed closing assets", "error", err)
+	}
+	world.Assets = NewAssetManager(fsys)
+	return world
+}
+
+// InitAudio starts mixing the world's audio into output. Audio persists
+// across Reset and is closed with the world.
+func (world *World) InitAudio(output AudioOutput) *Audio {
+	if world.Audio != nil {
+		return world.Audio
+	}
+	world.Audio = NewAudio(world.Assets, output)
+	world.Audio.rand = world.RNG.Stream("engine.Audio")
+	return world.Audio
+}
+
 func (world *World) AddSystems(systems ...System) *World {
 	for _, system := range systems {
 		switch system.(type) {
@@ -84,17 +145,41 @@ func (world *World) AddSystems(systems ...System) *World {
 
 func (world *World) CreateEntity(components ...any) uint32 {
 	entity := world.components.createEntity(components...)
+	world.profiler.churn.EntitiesCreated++
+	world.profiler.churn.ComponentsAdded += len(components)
+	if world.history.recording() {
+		world.history.record(historyOp{kind: entityCreated, entity: entity})
+		for _, component := range components {
+			world.history.recordComponent(entity, reflect.TypeOf(component), nil, component)
+		}
+	}
 	var wg sync.WaitGroup
 	wg.Add(len(world.groups))
 	for _, group := range world.groups {
 		group.EvaluateEntity(entity, world.components, &wg)
 	}
 	wg.Wait()
+	for _, component := range components {
+		world.changed(entity, reflect.TypeOf(component))
+	}
 	return entity
 }
 
 func (world *World) DeleteEntity(entity uint32) {
+	if world.components.entities.Contains(entity) {
+		world.deleteChildren(entity)
+		world.profiler.churn.EntitiesDeleted++
+	}
+	if world.history.recording() && world.components.entities.Contains(entity) {
+		for t, idx := range world.components.registry {
+			if component, ok := world.components.componentSets[idx].get(entity); ok {
+				world.history.recordComponent(entity, t, component, nil)
+			}
+		}
+		world.history.record(historyOp{kind: entityDeleted, entity: entity})
+	}
 	world.components.deleteEntity(entity)
+	world.deleted(entity)
 	var wg sync.WaitGroup
 	wg.Add(len(world.groups))
 	for _, group := range world.groups {
@@ -113,30 +198,31 @@ func (world *World) GetGroup(m Matcher) *Group {
 
 func (world *World) GetUniqueComponent(t reflect.Type) any {
 	if !world.components.hasComponent(t) {
-		slog.Error("Component not found in component storage")
+		world.Logger.Error("Component not found in component storage", "component", t)
 		return nil
 	}
 	set := world.components.getComponentSet(t)
-	if set.components.Len() != 1 {
-		slog.Error(fmt.Sprintf("Expected 1 entity in component set, found %d", set.components.Len()))
+	if set.len() != 1 {
+		world.Logger.Error("Expected 1 entity in component set", "component", t, "entities", set.len())
 		return nil
 	}
-	_, component, _ := set.components.Iterator().Next()
+	id, _ := set.first()
+	component, _ := set.get(id)
 	return component
 }
 
 func (world *World) ReplaceUniqueComponent(component any) {
 	t := reflect.TypeOf(component)
 	if !world.components.hasComponent(t) {
-		slog.Error("Component not found in component storage")
+		world.Logger.Error("Component not found in component storage", "component", t)
 		return
 	}
 	set := world.components.getComponentSet(t)
-	if set.components.Len() != 1 {
-		slog.Error(fmt.Sprintf("Expected 1 entity in component set, found %d", set.components.Len()))
+	if set.len() != 1 {
+		world.Logger.Error("Expected 1 entity in component set", "component", t, "entities", set.len())
 		return
 	}
-	id, _, _ := set.components.Iterator().Next()
+	id, _ := set.first()
 	world.ReplaceComponent(id, component)
 }
 
@@ -145,29 +231,40 @@ func (world *World) GetEntityComponent(entity uint32, t reflect.Type) (any, bool
 		return nil, false
 	}
 	set := world.components.getComponentSet(t)
-	if set.components.Contains(entity) {
+	if set.contains(entity) {
 		return set.getComponent(entity), true
 	}
 	return nil, false
 }
 
+// HasComponent reports whether entity has a component of type t. It is the
+// natural way to check for tags.
+func (world *World) HasComponent(entity uint32, t reflect.Type) bool {
+	return world.components.hasComponent(t) && world.components.getComponentSet(t).contains(entity)
+}
+
 func (world *World) ReplaceComponent(entity uint32, component any) {
 	if !world.components.hasComponent(reflect.TypeOf(component)) {
 		world.AddComponent(entity, component)
 		return
 	}
 	set := world.components.getComponentSet(reflect.TypeOf(component))
-	if !set.components.Contains(entity) {
+	if !set.contains(entity) {
 		world.AddComponent(entity, component)
 		return
 	}
+	if world.history.recording() {
+		world.history.recordComponent(entity, reflect.TypeOf(component), set.getComponent(entity), component)
+	}
 	set.replaceComponent(entity, component)
+	world.profiler.churn.ComponentsReplaced++
 	var wg sync.WaitGroup
 	wg.Add(len(world.groups))
 	for _, group := range world.groups {
 		group.EvaluateEntity(entity, world.components, &wg)
 	}
 	wg.Wait()
+	world.changed(entity, reflect.TypeOf(component))
 }
 
 func (world *World) AddComponent(entity uint32, component any) {
@@ -175,17 +272,22 @@ func (world *World) AddComponent(entity uint32, component any) {
 		world.components.registerComponent(reflect.TypeOf(component))
 	}
 	set := world.components.getComponentSet(reflect.TypeOf(component))
-	if set.components.Contains(entity) {
-		slog.Error("Entity already registered in component storage", "stack", getStack())
+	if set.contains(entity) {
+		world.EntityLogger(entity).Error("Entity already registered in component storage", "stack", debug.Stack())
 		return
 	}
 	set.addComponent(entity, component)
+	world.profiler.churn.ComponentsAdded++
+	if world.history.recording() {
+		world.history.recordComponent(entity, reflect.TypeOf(component), nil, component)
+	}
 	var wg sync.WaitGroup
 	wg.Add(len(world.groups))
 	for _, group := range world.groups {
 		group.EvaluateEntity(entity, world.components, &wg)
 	}
 	wg.Wait()
+	world.changed(entity, reflect.TypeOf(component))
 }
 
 func (world *World) RemoveComponent(entity uint32, t reflect.Type) {
@@ -193,30 +295,48 @@ func (world *World) RemoveComponent(entity uint32, t reflect.Type) {
 		return
 	}
 	set := world.components.getComponentSet(t)
-	if !set.components.Contains(entity) {
+	if !set.contains(entity) {
 		return
 	}
+	if world.history.recording() {
+		world.history.recordComponent(entity, t, set.getComponent(entity), nil)
+	}
 	set.removeEntity(entity)
+	world.profiler.churn.ComponentsRemoved++
 	var wg sync.WaitGroup
 	wg.Add(len(world.groups))
 	for _, group := range world.groups {
 		group.EvaluateEntity(entity, world.components, &wg)
 	}
 	wg.Wait()
+	world.changed(entity, t)
 }
 
+// Simulate runs frames until the world stops. If it stopped because systems
+// with the StopWorld policy failed, their joined SystemErrors are returned.
 func (world *World) Simulate() error {
 	if world.Window == nil {
 		panic("Window not initialised. Call InitWindow(width, height) first")
 	}
-	world.initialize()
-	world.CreateEntity(InputComponent{KeyState: make(map[sdl.Keycode]bool)})
+	world.stopErrors = nil
+	if len(world.transitions) > 0 {
+		if err := world.applySceneTransitions(); err != nil {
+			return err
+		}
+	} else {
+		world.initialize()
+	}
 
 	surface, _ := world.Window.GetSurface()
 
 	for world.running {
 		input := make(map[sdl.Keycode]bool)
+		var text strings.Builder
 		for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
+			if typed, ok := event.(*sdl.TextInputEvent); ok {
+				text.WriteString(typed.GetText())
+				continue
+			}
 			key, state := world.handleEvent(event)
 			if key == sdl.K_UNKNOWN {
 				continue
@@ -227,27 +347,36 @@ func (world *World) Simulate() error {
 				input[key] = true
 			}
 		}
-		world.ReplaceUniqueComponent(InputComponent{KeyState: input})
+		InsertResource(world, Input{KeyState: input, Text: text.String()})
 		if err := surface.FillRect(nil, 0); err != nil {
-			slog.Error("Failed filling surface", "error", err)
+			world.Logger.Error("Failed filling surface", "error", err)
 		}
 		loopTime := world.loop()
 		if err := world.Window.UpdateSurface(); err != nil {
-			slog.Error("Failed updating surface", "error", err)
+			world.Logger.Error("Failed updating surface", "error", err)
+		}
+		if err := world.applySceneTransitions(); err != nil {
+			world.running = false
+			return err
 		}
 
-		if loopTime < uint32(world.Time.Timestep.Milliseconds()) {
-			delay := uint32(world.Time.Timestep.Milliseconds()) - loopTime
+		clock, err := Resource[Time](world)
+		if err != nil {
+			world.running = false
+			return err
+		}
+		if loopTime < uint32(clock.Timestep.Milliseconds()) {
+			delay := uint32(clock.Timestep.Milliseconds()) - loopTime
 			sdl.Delay(delay)
 		}
 	}
-	return nil
+	return errors.Join(world.stopErrors...)
 }
 
 func (world *World) handleEvent(event sdl.Event) (sdl.Keycode, uint8) {
 	switch t := event.(type) {
 	case *sdl.QuitEvent:
-		println("Quitting..")
+		world.Logger.Info("Quitting")
 		world.running = false
 	case *sdl.KeyboardEvent:
 		return t.Keysym.Sym, t.State
@@ -257,35 +386,74 @@ func (world *World) handleEvent(event sdl.Event) (sdl.Keycode, uint8) {
 
 func (world *World) loop() uint32 {
 	startTime := sdl.GetTicks64()
+	world.Assets.Poll()
+	if world.inspector != nil {
+		world.inspector.poll()
+	}
 	world.update()
-	world.Time.update()
+	if clock, err := ResourceMut[Time](world); err == nil {
+		clock.update()
+	}
 	return uint32(sdl.GetTicks64() - startTime)
 }
 
+// Stop makes Simulate return after the current frame.
+func (world *World) Stop() {
+	world.running = false
+}
+
 func (world *World) Reset() error {
 	if !world.running {
 		return nil
 	}
 	world.running = false
+	world.clear()
+	world.running = true
+
+	return nil
+}
+
+func (world *World) clear() {
+	for i := len(world.scenes) - 1; i >= 0; i-- {
+		world.exitScene()
+		frame := world.scenes[i]
+		world.scene = frame.scene
+		world.systems = frame.systems
+		world.resources = frame.resources
+	}
+	world.scenes = nil
+	world.transitions = nil
+	world.scene = nil
 
 	world.resetSystems()
+	world.Assets.Collect()
 	world.components = NewComponentStorage()
 	world.groups = make(map[Matcher]*Group)
-
-	world.running = true
-
-	return nil
+	world.history = nil
+	world.clearEvents()
 }
 
 func (world *World) Close() error {
-	world.Reset()
+	if world.inspector != nil {
+		if err := world.inspector.Close(); err != nil {
+			world.Logger.Error("Failed closing inspector", "error", err)
+		}
+	}
+	world.clear()
+	if world.Audio != nil {
+		if err := world.Audio.Close(); err != nil {
+			world.Logger.Error("Failed closing audio", "error", err)
+		}
+		world.Audio = nil
+	}
+	if err := world.Assets.Close(); err != nil {
+		world.Logger.Error("Failed closing assets", "error", err)
+	}
 
 	ttf.Quit()
 	sdl.Quit()
 	if err := world.Window.Destroy(); err != nil {
-		slog.Error(
-			"Failed destroying window",
-			"stack", getStack())
+		world.Logger.Error("Failed destroying window", "error", err)
 	}
 	worldInstance = nil
 	return nil
@@ -299,60 +467,38 @@ func (world *World) resetSystems() {
 		}
 	}
 	for system := range systems {
-		if closer, ok := system.(io.Closer); ok {
-			if err := closer.Close(); err != nil {
-				slog.Error(
-					"Failed closing system %s",
-					"stack", getStack(),
-				)
-			}
-		}
+		world.closeSystem(system)
 	}
 	world.systems = map[SystemType][]System{}
 }
 
 func (world *World) initialize() {
 	for _, system := range world.systems[Initialize] {
-		initialize := func() {
-			defer handlePanic()
-			if err := system.(InitializeSystem).Initialize(world); err != nil {
-				slog.Error(
-					"Failed initializing system",
-					"stack", getStack(),
-				)
-			}
+		if world.removed[system] {
+			continue
 		}
-		initialize()
+		start := time.Now()
+		world.run(system, Initialize, func() error {
+			return system.(InitializeSystem).Initialize(world)
+		})
+		world.profiler.system(system).initialize = time.Since(start)
 	}
 }
 
 func (world *World) update() {
+	frameStart := time.Now()
 	for _, system := range world.systems[Update] {
-		update := func() {
-			defer handlePanic()
-			if err := system.(UpdateSystem).Update(world); err != nil {
-				slog.Error(
-					"Failed updating system",
-					"stack", debug.Stack(),
-				)
-			}
+		if !world.shouldRun(system) {
+			continue
 		}
-		update()
-	}
-}
-
-func handlePanic() {
-	if r := recover(); r != nil {
-		slog.Error(
-			"Panic",
-			"recover", r,
-			"stack", getStack(),
-		)
-	}
-}
-
-func getStack() []byte {
-	buf := make([]byte, 1<<20)
-	len := runtime.Stack(buf, true)
-	return buf[:len]
+		start := time.Now()
+		world.run(system, Update, func() error {
+			return system.(UpdateSystem).Update(world)
+		})
+		world.profiler.system(system).update.add(time.Since(start))
+	}
+	world.profiler.endFrame(time.Since(frameStart), world.groups)
+	world.logContext.frame.Store(world.profiler.frames)
+	clear(world.removed)
+	world.swapEvents()
 }
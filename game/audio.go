@@ -6,122 +6,52 @@ import (
 	"time"
 
 	"github.com/gopxl/beep"
-	"github.com/gopxl/beep/effects"
-	"github.com/gopxl/beep/mp3"
-	"github.com/gopxl/beep/speaker"
-	"github.com/gopxl/beep/wav"
+	"github.com/lakrsv/parkour-engine/engine"
 )
 
 const (
 	Rate beep.SampleRate = 44100
 )
 
-var pickupColorSound *beep.Buffer
-var walkSound *beep.Buffer
-var goalSound *beep.Buffer
-var doorOpenSounds []beep.Buffer
-
-func InitAudio() {
-	if err := speaker.Init(Rate, Rate.N(time.Second/10)); err != nil {
-		slog.Error("Failed initializing speaker", "error", err)
-		return
-	}
-	pickupColorSound = loadSound("assets/audio/pickup_color.wav")
-	walkSound = loadSound("assets/audio/walk.wav")
-	goalSound = loadSound("assets/audio/goal.wav")
-	doorOpenSounds = loadDoorOpenSounds()
-}
-
-func loadDoorOpenSounds() []beep.Buffer {
-	var buffers []beep.Buffer
-	for i := range 11 {
-		sound := loadSound(fmt.Sprintf("assets/audio/door_open_%d.wav", i))
-		if sound != nil {
-			buffers = append(buffers, *sound)
-		}
-	}
-	return buffers
-}
+// Sound bank names gameplay code requests with engine.PlaySound.
+const (
+	PickupColorSound = "PickupColor"
+	WalkSound        = "Walk"
+	GoalSound        = "Goal"
+	DoorOpenSound    = "DoorOpen"
+	BumpSound        = "Bump"
+)
 
-func loadSound(path string) *beep.Buffer {
-	f, err := content.Open(path)
+func InitAudio(w *engine.World) {
+	var output engine.AudioOutput
+	speakerOutput, err := engine.NewSpeakerOutput(Rate, time.Second/10)
 	if err != nil {
-		slog.Error("Failed opening audio file", "path", path, "error", err)
-		return nil
+		slog.Error("Failed initializing speaker, continuing without sound", "error", err)
+		output = engine.NewNullOutput(Rate, time.Second/10)
+	} else {
+		output = speakerOutput
 	}
-	defer func() {
-		if err := f.Close(); err != nil {
-			slog.Error("Failed closing audio file", "path", path, "error", err)
-		}
-	}()
-
-	streamer, format, err := wav.Decode(f)
-	if err != nil {
-		slog.Error("Failed decoding wav", "error", err)
-		return nil
+	audio := w.InitAudio(output)
+	audio.SetBusVolume(engine.MusicBus, 0.25)
+	doorOpenSounds := make([]string, 11)
+	for i := range doorOpenSounds {
+		doorOpenSounds[i] = fmt.Sprintf("audio/door_open_%d.wav", i)
 	}
-	defer func() {
-		if err := streamer.Close(); err != nil {
-			slog.Error("Failed closing streamer", "error", err)
-		}
-	}()
-
-	sound := beep.NewBuffer(format)
-	sound.Append(streamer)
-	return sound
-}
-
-func PlayBackgroundMusic() {
-	idx := 0
-	for {
-		f, err := content.Open(fmt.Sprintf("assets/audio/background_%d.mp3", idx%2))
-		if err != nil {
-			slog.Error("Failed opening audio", "error", err)
-			return
-		}
-		streamer, format, err := mp3.Decode(f)
-		if err != nil {
-			slog.Error("Failed decoding mp3", "error", err)
-			return
+	for _, bank := range []engine.SoundBank{
+		{Name: PickupColorSound, Sounds: []string{"audio/pickup_color.wav"}},
+		{Name: WalkSound, Sounds: []string{"audio/walk.wav"}, Volume: 0.5},
+		{Name: BumpSound, Sounds: []string{"audio/walk.wav"}, Volume: 0.2},
+		{Name: GoalSound, Sounds: []string{"audio/goal.wav"}, Volume: 0.7},
+		{Name: DoorOpenSound, Sounds: doorOpenSounds, Mode: engine.RoundRobin, Volume: 0.7},
+	} {
+		if err := audio.AddBank(bank); err != nil {
+			slog.Error("Failed adding sound bank", "bank", bank.Name, "error", err)
 		}
-		defer streamer.Close()
-
-		resampled := beep.Resample(4, format.SampleRate, Rate, streamer)
-		volume := &effects.Volume{Streamer: resampled, Base: 2, Volume: -2}
-		done := make(chan bool)
-		speaker.Play(beep.Seq(volume, beep.Callback(func() {
-			done <- true
-		})))
-		<-done
-		time.Sleep(5 * time.Second)
-		idx += 1
 	}
-}
-
-func playPickupSound() {
-	streamer := pickupColorSound.Streamer(0, pickupColorSound.Len())
-	resampled := beep.Resample(4, pickupColorSound.Format().SampleRate, Rate, streamer)
-	speaker.Play(resampled)
-}
-
-func playWalkSound() {
-	streamer := walkSound.Streamer(0, walkSound.Len())
-	resampled := beep.Resample(4, walkSound.Format().SampleRate, Rate, streamer)
-	volume := &effects.Volume{Streamer: resampled, Base: 2, Volume: -1}
-	speaker.Play(volume)
-}
-
-func playDoorOpenSound(num int) {
-	soundIndex := num % len(doorOpenSounds)
-	streamer := doorOpenSounds[soundIndex].Streamer(0, doorOpenSounds[soundIndex].Len())
-	resampled := beep.Resample(4, doorOpenSounds[soundIndex].Format().SampleRate, Rate, streamer)
-	volume := &effects.Volume{Streamer: resampled, Base: 2, Volume: -0.5}
-	speaker.Play(volume)
-}
-
-func playGoalSound() {
-	streamer := goalSound.Streamer(0, goalSound.Len())
-	resampled := beep.Resample(4, goalSound.Format().SampleRate, Rate, streamer)
-	volume := &effects.Volume{Streamer: resampled, Base: 2, Volume: -0.5}
-	speaker.Play(volume)
+	audio.SetPolyphony("audio/walk.wav", 2)
+	audio.PlayMusic(engine.Playlist{
+		Tracks:    []string{"audio/background_0.mp3", "audio/background_1.mp3"},
+		Loop:      true,
+		Crossfade: 5 * time.Second,
+	})
 }
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/lakrsv/parkour-engine/engine"
+)
+
+// registerCommands adds the game's console commands to the engine's.
+func registerCommands(w *engine.World) {
+	w.Commands.Register(engine.Command{Name: "level", Usage: "level <number>", Run: levelCommand})
+	w.Commands.Register(engine.Command{Name: "spawn", Usage: "spawn <prefab> <x> <y> [color]", Run: spawnCommand, Complete: completeSpawn})
+	w.Commands.Register(engine.Command{Name: "give", Usage: "give color <color>", Run: giveCommand, Complete: completeGive})
+	w.Commands.Register(engine.Command{Name: "noclip", Usage: "noclip", Run: noclipCommand})
+}
+
+func levelCommand(w *engine.World, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("usage: level <number>")
+	}
+	level, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", fmt.Errorf("invalid level %q", args[0])
+	}
+	text, err := w.Assets.Text(levelPath(level))
+	if err != nil {
+		return "", err
+	}
+	text.Release()
+	w.ReplaceScene(&LevelScene{Level: level})
+	return fmt.Sprintf("loading level %d", level), nil
+}
+
+// spawnCommand spawns a prefab on the tilemap. Background prefabs replace the
+// entity in their cell; the others need a free cell.
+func spawnCommand(w *engine.World, args []string) (string, error) {
+	if len(args) < 3 || len(args) > 4 {
+		return "", errors.New("usage: spawn <prefab> <x> <y> [color]")
+	}
+	x, errX := strconv.Atoi(args[1])
+	y, errY := strconv.Atoi(args[2])
+	if errX != nil || errY != nil {
+		return "", fmt.Errorf("invalid position %s %s", args[1], args[2])
+	}
+	tilemap, err := engine.ResourceMut[engine.Tilemap](w)
+	if err != nil {
+		return "", err
+	}
+	cell := engine.Cell{X: x, Y: y}
+	if !tilemap.InBounds(cell) {
+		return "", fmt.Errorf("%d %d is outside the level", x, y)
+	}
+
+	components, err := w.Prefabs.Components(args[0], nil)
+	if err != nil {
+		return "", err
+	}
+	has := func(component any) bool {
+		return slices.ContainsFunc(components, func(c any) bool { return reflect.TypeOf(c) == reflect.TypeOf(component) })
+	}
+	layer := BackgroundLayer
+	if i := slices.IndexFunc(components, func(c any) bool { _, ok := c.(engine.TileLayer); return ok }); i >= 0 {
+		layer = string(components[i].(engine.TileLayer))
+	}
+	overrides := engine.Overrides{"Position": PositionComponent{X: x, Y: y}}
+	var color Color
+	if len(args) == 4 {
+		var ok bool
+		color, ok = colorNames[args[3]]
+		if !ok {
+			return "", fmt.Errorf("unknown color %q", args[3])
+		}
+		overrides["Color"] = ColorComponent{Color: color}
+		if has(SummonPickupComponent{}) {
+			overrides["SummonPickup"] = SummonPickupComponent{Color: color}
+		}
+		if has(SummonComponent{}) {
+			overrides["Summon"] = SummonComponent{Color: color}
+		}
+	}
+	if has(engine.Collider{}) {
+		overrides["engine.Collider"] = colliderOf(args[0], color)
+	}
+
+	if other, ok := tilemap.At(layer, cell); ok {
+		if layer != BackgroundLayer {
+			return "", fmt.Errorf("%d %d is taken", x, y)
+		}
+		w.DeleteEntity(other)
+	}
+	entity, err := w.Spawn(args[0], overrides)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("spawned %s %d at %d %d", args[0], entity, x, y), nil
+}
+
+func completeSpawn(w *engine.World, args []string) []string {
+	switch len(args) {
+	case 1:
+		return w.Prefabs.Names()
+	case 4:
+		return slices.Collect(maps.Keys(colorNames))
+	}
+	return nil
+}
+
+// giveCommand gives the player a summon color as if it had been picked up.
+func giveCommand(w *engine.World, args []string) (string, error) {
+	if len(args) != 2 || args[0] != "color" {
+		return "", errors.New("usage: give color <color>")
+	}
+	color, ok := colorNames[args[1]]
+	if !ok {
+		return "", fmt.Errorf("unknown color %q", args[1])
+	}
+	player, ok := w.FindByName(PlayerName)
+	if !ok {
+		return "", errors.New("there is no player")
+	}
+	w.ReplaceComponent(player, SummonComponent{Color: color})
+	engine.Send(w, ColorPickedUp{Entity: player, Color: color})
+	return "gave " + args[1], nil
+}
+
+func completeGive(w *engine.World, args []string) []string {
+	switch len(args) {
+	case 1:
+		return []string{"color"}
+	case 2:
+		return slices.Collect(maps.Keys(colorNames))
+	}
+	return nil
+}
+
+func noclipCommand(w *engine.World, args []string) (string, error) {
+	player, ok := w.FindByName(PlayerName)
+	if !ok {
+		return "", errors.New("there is no player")
+	}
+	if w.HasComponent(player, reflect.TypeOf(NoclipComponent{})) {
+		w.RemoveComponent(player, reflect.TypeOf(NoclipComponent{}))
+		return "noclip off", nil
+	}
+	w.AddComponent(player, NoclipComponent{})
+	return "noclip on", nil
+}
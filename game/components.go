@@ -1,9 +1,72 @@
 package main
 
 import (
+	"maps"
+	"slices"
+
 	"github.com/lakrsv/parkour-engine/engine"
 )
 
+// Components and resources are registered under stable names so save games keep loading
+// when the Go types move around.
+func init() {
+	engine.RegisterComponent[PlayerInputComponent]("PlayerInput")
+	engine.RegisterComponent[SummonInputComponent]("SummonInput")
+	engine.RegisterComponent[RenderComponent]("Render")
+	engine.RegisterComponent[SummonComponent]("Summon")
+	engine.RegisterComponent[CreateSummonComponent]("CreateSummon")
+	engine.RegisterComponent[ColorComponent]("Color")
+	engine.RegisterComponent[SummonPickupComponent]("SummonPickup")
+	engine.RegisterComponent[FloorComponent]("Floor")
+	engine.RegisterComponent[PositionComponent]("Position")
+	engine.RegisterComponent[MoveComponent]("Move")
+	engine.RegisterComponent[InteractsWithTriggersComponent]("InteractsWithTriggers")
+	engine.RegisterComponent[FacingComponent]("Facing")
+	engine.RegisterComponent[DeferDoorRenderComponent]("DeferDoorRender")
+	engine.RegisterComponent[TriggerComponent]("Trigger")
+	engine.RegisterComponent[TriggeredComponent]("Triggered")
+	engine.RegisterComponent[ObstacleComponent]("Obstacle")
+	engine.RegisterComponent[DirectionIndicatorComponent]("DirectionIndicator")
+	engine.RegisterComponent[NoclipComponent]("Noclip")
+	engine.RegisterComponent[engine.Relative[PositionComponent]]("RelativePosition")
+
+	engine.RegisterResource[Level]("Level")
+}
+
+// The tilemap layers. Tiles are in the background, the player and the summons
+// in the foreground and the direction indicators in the effect layer.
+const (
+	BackgroundLayer = "background"
+	ForegroundLayer = "foreground"
+	EffectLayer     = "effect"
+)
+
+// allBarriers are the collision layers of the barriers, one per color in
+// colorNames.
+var allBarriers uint32 = 1<<len(colorNames) - 1
+
+// barrierLayer returns the collision layer of barriers of color, or every
+// layer for an unknown color.
+func barrierLayer(color Color) uint32 {
+	for i, name := range slices.Sorted(maps.Keys(colorNames)) {
+		if colorNames[name] == color {
+			return 1 << i
+		}
+	}
+	return allBarriers
+}
+
+// colliderOf returns the Collider of an entity spawned from prefab in color.
+func colliderOf(prefab string, color Color) engine.Collider {
+	switch prefab {
+	case "Barrier":
+		return engine.Collider{Layer: barrierLayer(color)}
+	case "Summon":
+		return engine.Collider{Mask: allBarriers &^ barrierLayer(color)}
+	}
+	return engine.Collider{Mask: allBarriers}
+}
+
 type PlayerInputComponent struct {
 }
 
@@ -11,13 +74,14 @@ type SummonInputComponent struct {
 	X, Y int
 }
 
-type LevelComponent struct {
-	Level  int
-	Header []string
+// Paused stops the gameplay systems while the level stays on screen.
+type Paused struct {
+	Paused bool
 }
 
-type DoorOpenPlayCountComponent struct {
-	Count int
+type Level struct {
+	Level  int
+	Header []string
 }
 
 type RenderComponent struct {
@@ -25,23 +89,17 @@ type RenderComponent struct {
 }
 
 type SummonComponent struct {
-	color struct {
-		R, G, B uint8
-	}
+	Color Color
 }
 
 type CreateSummonComponent struct{}
 
 type ColorComponent struct {
-	color struct {
-		R, G, B uint8
-	}
+	Color Color
 }
 
 type SummonPickupComponent struct {
-	color struct {
-		R, G, B uint8
-	}
+	Color Color
 }
 
 type FloorComponent struct{}
@@ -50,20 +108,35 @@ type PositionComponent struct {
 	X, Y int
 }
 
+func (p PositionComponent) Cell() engine.Cell {
+	return engine.Cell{X: p.X, Y: p.Y}
+}
+
+func (p PositionComponent) Place(cell engine.Cell) PositionComponent {
+	return PositionComponent{X: cell.X, Y: cell.Y}
+}
+
+func (p PositionComponent) Offset(offset PositionComponent) PositionComponent {
+	return PositionComponent{X: p.X + offset.X, Y: p.Y + offset.Y}
+}
+
 type MoveComponent struct {
 	X, Y int
 }
 
 type InteractsWithTriggersComponent struct {
-	color struct {
-		R, G, B uint8
-	}
+	Color Color
 }
 
 type FacingComponent struct {
 	X, Y int
 }
 
+// DirectionIndicatorComponent marks the arrow showing where its parent is
+// facing.
+type DirectionIndicatorComponent struct {
+}
+
 type DeferDoorRenderComponent struct {
 }
 
@@ -74,25 +147,11 @@ type TriggerComponent struct {
 
 type TriggeredComponent struct {
 	Symbol rune
-	Action func(entity uint32, w *engine.World)
-}
-
-type GridComponent struct {
-	Width, Height      int
-	BackgroundEntities []uint32
-	ForegroundEntities []uint32
-	EffectEntities     []uint32
+	Action TriggerAction
 }
 
 type ObstacleComponent struct {
 }
 
-func (g GridComponent) GetCell(x, y int) int {
-	return y*g.Width + x
-}
-
-func (g GridComponent) GetPosition(cell int) (x int, y int) {
-	x = cell % g.Width
-	y = cell / g.Width
-	return x, y
-}
+// NoclipComponent lets an entity walk through obstacles.
+type NoclipComponent struct{}
@@ -0,0 +1,25 @@
+package main
+
+// ButtonPressed is sent when a button is stepped on and fires its doors.
+type ButtonPressed struct {
+	Symbol rune
+}
+
+type DoorOpened struct {
+	Door uint32
+}
+
+type DoorClosed struct {
+	Door uint32
+}
+
+// ColorPickedUp is sent when a summoner takes on the color of a pickup.
+type ColorPickedUp struct {
+	Entity uint32
+	Color  Color
+}
+
+// LevelCompleted is sent when the exit is reached.
+type LevelCompleted struct {
+	Level int
+}
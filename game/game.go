@@ -2,31 +2,59 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"io/fs"
-	"math"
+	"log/slog"
+	"reflect"
 	"strings"
 	"unicode"
 
 	"github.com/lakrsv/parkour-engine/engine"
+	"github.com/veandco/go-sdl2/sdl"
 )
 
-func Run(level int) {
-	// TODO: Reuse world so we don't get multiple windows
-	w := engine.GetInstance()
-	w.InitWindow("Colormancer", 800, 480)
+// LevelScene plays one level. Finishing or restarting a level replaces the
+// scene with a fresh LevelScene.
+type LevelScene struct {
+	Level int
+	// Checkpoint, if set, is restored instead of loading the level file.
+	Checkpoint *engine.Snapshot
+}
+
+func (s *LevelScene) Setup(w *engine.World) error {
+	if err := w.LoadPrefabs(prefabsPath); err != nil {
+		return err
+	}
+	if s.Checkpoint != nil {
+		return w.Restore(s.Checkpoint)
+	}
+	if err := loadLevel(s.Level, w); err != nil {
+		return err
+	}
+	if err := validateLevel(w); err != nil {
+		slog.Warn("Level is not solvable", "level", s.Level, "error", err)
+	}
+	return nil
+}
 
-	grid := loadLevel(level, w)
-	w.AddSystems(
-		&DeferDoorRenderSystem{},
+func (s *LevelScene) Systems() []engine.System {
+	gameplay := []engine.System{
 		&PlayerInputSystem{},
 		&CreateSummonSystem{},
 		&SummonInputSystem{},
 		&MoveSystem{},
 		&SummonPickupSystem{},
 		&TriggerSystem{},
+	}
+	systems := []engine.System{&DeferDoorRenderSystem{}, &PauseSystem{Gameplay: gameplay}}
+	systems = append(systems, gameplay...)
+	return append(systems,
 		&DirectionIndicatorSystem{},
+		&LevelReloadSystem{},
+		&SoundEffectSystem{},
+		&LevelProgressSystem{},
+		&engine.AudioSystem{},
 		&RenderSystem{palette: NewRunePalette(
 			map[rune]Color{
 				Floor:           Color{R: 255, G: 255, B: 255},
@@ -42,33 +70,115 @@ func Run(level int) {
 				LeftIndicator:   Color{R: 0, G: 255, B: 0},
 				RightIndicator:  Color{R: 0, G: 255, B: 0},
 				Exit:            Color{R: 255, G: 255, B: 255},
+				Box:             Color{R: 205, G: 133, B: 63},
 			}),
 		},
+		&engine.StatsOverlaySystem{Font: "fonts/consolas.ttf", Size: 12, Key: sdl.K_F3},
+		&engine.ConsoleSystem{Font: "fonts/consolas.ttf", Size: 14, Key: sdl.K_BACKQUOTE, Lines: 8},
 	)
-	_ = w.CreateEntity(
-		*grid,
-	)
-	_ = w.CreateEntity(
-		DoorOpenPlayCountComponent{},
-	)
+}
 
-	if err := w.Simulate(); err != nil {
-		panic(err)
+var (
+	levelEntitiesMatcher = &engine.AnyOfComponentMatcher{Components: []reflect.Type{
+		reflect.TypeOf(PositionComponent{}),
+	}}
+)
+
+var ErrBlocked = errors.New("cell is blocked by an obstacle")
+
+// trackTiles puts the entities on the Tilemap and adds the rules for moving
+// on it.
+func trackTiles(w *engine.World) {
+	engine.TrackTiles(w, PositionComponent.Cell, PositionComponent.Place)
+	w.AddOccupancyRule(obstacleRule)
+	w.AddOccupancyRule(collideRule)
+}
+
+// obstacleRule keeps entities out of cells with an obstacle in the
+// background, unless they have noclip.
+func obstacleRule(w *engine.World, entity uint32, cell engine.Cell) error {
+	tilemap, err := engine.ResourceMut[engine.Tilemap](w)
+	if err != nil {
+		return err
+	}
+	if blocked(w, tilemap, cell) && !w.HasComponent(entity, reflect.TypeOf(NoclipComponent{})) {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// collideRule is engine.Collide for entities without noclip.
+func collideRule(w *engine.World, entity uint32, cell engine.Cell) error {
+	if w.HasComponent(entity, reflect.TypeOf(NoclipComponent{})) {
+		return nil
 	}
+	return engine.Collide(w, entity, cell)
+}
+
+// blocked reports whether there is an obstacle in the background of cell.
+func blocked(w *engine.World, tilemap *engine.Tilemap, cell engine.Cell) bool {
+	background, ok := tilemap.At(BackgroundLayer, cell)
+	return ok && w.HasComponent(background, reflect.TypeOf(ObstacleComponent{}))
+}
+
+// prefabsPath holds the prefabs levels are built from.
+const prefabsPath = "prefabs.yaml"
+
+func levelPath(level int) string {
+	return fmt.Sprintf("levels/level_%d.txt", level)
 }
 
-func loadLevel(level int, w *engine.World) *GridComponent {
-	file, e := content.Open(fmt.Sprintf("assets/levels/level_%d.txt", level))
-	defer func(file fs.File) {
-		err := file.Close()
-		if err != nil {
-			panic(err)
+// reloadLevel rebuilds the current level's entities from the prefabs and its
+// level file in place. The player keeps its position if that cell is still
+// free.
+func reloadLevel(w *engine.World) error {
+	level, err := engine.Resource[Level](w)
+	if err != nil {
+		return err
+	}
+
+	var previousPosition *PositionComponent
+	if player, ok := w.FindByName(PlayerName); ok {
+		if positionComponent, ok := w.GetEntityComponent(player, reflect.TypeOf(PositionComponent{})); ok {
+			position := reflect.ValueOf(positionComponent).Interface().(PositionComponent)
+			previousPosition = &position
 		}
-	}(file)
-	if e != nil {
-		panic(e)
 	}
 
+	if err := w.LoadPrefabs(prefabsPath); err != nil {
+		slog.Error("Failed reloading prefabs", "error", err)
+		return nil
+	}
+
+	// The history refers to the entities that are about to be replaced.
+	w.History().Clear()
+	for _, entity := range w.GetGroup(levelEntitiesMatcher).GetEntities() {
+		w.DeleteEntity(entity)
+	}
+	if err := loadLevel(level.Level, w); err != nil {
+		slog.Error("Failed reloading level", "level", level.Level, "error", err)
+		return nil
+	}
+
+	if previousPosition == nil {
+		return nil
+	}
+	if player, ok := w.FindByName(PlayerName); ok && w.CanOccupy(player, previousPosition.Cell()) == nil {
+		w.ReplaceComponent(player, *previousPosition)
+	}
+	return nil
+}
+
+// loadLevel inserts the level's Tilemap and spawns its tiles, the player and
+// the pickups on it.
+func loadLevel(level int, w *engine.World) error {
+	text, err := w.Assets.Text(levelPath(level))
+	if err != nil {
+		return err
+	}
+	defer text.Release()
+	file := strings.NewReader(text.Get())
+
 	// Load config
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
@@ -106,7 +216,7 @@ func loadLevel(level int, w *engine.World) *GridComponent {
 	}
 
 	// Load header
-	if _, err := file.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
 		panic(err)
 	}
 	scanner = bufio.NewScanner(file)
@@ -132,9 +242,7 @@ func loadLevel(level int, w *engine.World) *GridComponent {
 		header = append(header, line)
 	}
 
-	_ = w.CreateEntity(
-		LevelComponent{Level: level, Header: header},
-	)
+	engine.InsertResource(w, Level{Level: level, Header: header})
 
 	width := 0
 	height := 0
@@ -149,9 +257,9 @@ func loadLevel(level int, w *engine.World) *GridComponent {
 		height += 1
 	}
 
-	grid := &GridComponent{Width: width, Height: height, BackgroundEntities: make([]uint32, width*height), ForegroundEntities: make([]uint32, width*height), EffectEntities: make([]uint32, width*height)}
+	engine.InsertResource(w, engine.NewTilemap(width, height, BackgroundLayer, ForegroundLayer, EffectLayer))
 
-	if _, err := file.(io.Seeker).Seek(0, io.SeekStart); err != nil {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
 		panic(err)
 	}
 	scanner = bufio.NewScanner(file)
@@ -182,99 +290,87 @@ func loadLevel(level int, w *engine.World) *GridComponent {
 			} else if err != nil {
 				panic(err)
 			}
-			x, y := grid.GetPosition(idx - cellOffset)
-			grid.ForegroundEntities[idx-cellOffset] = math.MaxUint32
-			grid.EffectEntities[idx-cellOffset] = math.MaxUint32
-
-			var components []any
-			components = append(components, getConfigComponents(config[char])...)
+			x, y := (idx-cellOffset)%width, (idx-cellOffset)/width
 
+			overrides := getConfigOverrides(config[char])
+			overrides["Position"] = PositionComponent{X: x, Y: y}
+			var prefab string
 			switch char {
 			case Floor:
-				components = append(components, FloorBlueprint(x, y)...)
-				grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-					components...,
-				)
+				prefab = "Floor"
 			case Wall:
-				components = append(components, WallBlueprint(x, y)...)
-				grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-					components...,
-				)
+				prefab = "Wall"
 			case Exit:
-				components = append(components, ExitBlueprint(x, y, level+1)...)
-				grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-					components...,
-				)
+				prefab = "Exit"
 			case Player:
-				grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-					FloorBlueprint(x, y)...,
-				)
-				components = append(components, PlayerBlueprint(x, y)...)
-				grid.ForegroundEntities[idx-cellOffset] = w.CreateEntity(
-					components...,
-				)
+				if _, err := w.Spawn("Floor", engine.Overrides{"Position": overrides["Position"]}); err != nil {
+					return err
+				}
+				overrides["engine.Collider"] = colliderOf("Player", Color{})
+				if _, err := w.Spawn("Player", overrides); err != nil {
+					return err
+				}
+			case Box:
+				if _, err := w.Spawn("Floor", engine.Overrides{"Position": overrides["Position"]}); err != nil {
+					return err
+				}
+				overrides["engine.Collider"] = colliderOf("Box", Color{})
+				if _, err := w.Spawn("Box", overrides); err != nil {
+					return err
+				}
 			case CyanSummon:
-				components = append(components, SummonPickupBlueprint(x, y, Color{R: 0, G: 255, B: 255})...)
-				grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-					components...,
-				)
+				prefab = "CyanSummonPickup"
 			case RedSummon:
-				components = append(components, SummonPickupBlueprint(x, y, Color{R: 255, G: 0, B: 0})...)
-				grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-					components...,
-				)
+				prefab = "RedSummonPickup"
 			case YellowSummon:
-				components = append(components, SummonPickupBlueprint(x, y, Color{R: 255, G: 255, B: 0})...)
-				grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-					components...,
-				)
+				prefab = "YellowSummonPickup"
 			default:
-				// Button
-				if unicode.IsLower(char) {
-					components = append(components, ButtonBlueprint(x, y, unicode.ToUpper(char))...)
-					grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-						components...,
-					)
+				if _, ok := config[char][BarrierModifier]; ok {
+					prefab = "Barrier"
+					color, _ := overrides["Color"].(ColorComponent)
+					overrides["engine.Collider"] = colliderOf(prefab, color.Color)
+				} else if unicode.IsLower(char) {
+					// Button
+					prefab = "Button"
+					overrides["Trigger"] = map[string]any{"Symbol": unicode.ToUpper(char)}
 				} else if unicode.IsUpper(char) {
 					if modifiers, ok := config[char]; ok {
 						if _, ok := modifiers[OpenDoorModifier]; ok {
-							components = append(components, OpenDoorBlueprint(x, y, char)...)
-							grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-								components...,
-							)
+							prefab = "OpenDoor"
 						} else if _, ok := modifiers[ClosedDoorModifier]; ok {
-							components = append(components, ClosedDoorBlueprint(x, y, char)...)
-							grid.BackgroundEntities[idx-cellOffset] = w.CreateEntity(
-								components...,
-							)
+							prefab = "ClosedDoor"
 						}
+						overrides["Triggered"] = map[string]any{"Symbol": char}
 					}
 				}
 			}
+			if prefab != "" {
+				if _, err := w.Spawn(prefab, overrides); err != nil {
+					return err
+				}
+			}
 			idx++
 		}
 	}
-	return grid
+	return nil
 }
 
-func getConfigComponents(modifiers map[string]string) []any {
-	var components []any
+// colorNames are the colors levels and console commands can name.
+var colorNames = map[string]Color{
+	"Cyan":   {R: 0, G: 255, B: 255},
+	"Green":  {R: 0, G: 255, B: 0},
+	"Red":    {R: 255, G: 0, B: 0},
+	"Yellow": {R: 255, G: 255, B: 0},
+}
+
+func getConfigOverrides(modifiers map[string]string) engine.Overrides {
+	overrides := engine.Overrides{}
 	if configColor, ok := modifiers[ColorModifier]; ok {
-		var color Color
-		switch configColor {
-		case "Cyan":
-			color = Color{R: 0, G: 255, B: 255}
-		case "Green":
-			color = Color{R: 0, G: 255, B: 0}
-		case "Red":
-			color = Color{R: 255, G: 0, B: 0}
-		case "Yellow":
-			color = Color{R: 255, G: 255, B: 0}
-		default:
+		color, ok := colorNames[configColor]
+		if !ok {
 			color = Color{R: 255, G: 255, B: 255}
 		}
-
-		components = append(components, ColorComponent{color: color})
+		overrides["Color"] = ColorComponent{Color: color}
 	}
-	return components
+	return overrides
 }
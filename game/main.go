@@ -2,13 +2,106 @@ package main
 
 import (
 	"embed"
+	"flag"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lakrsv/parkour-engine/engine"
 )
 
 //go:embed assets/*
 var content embed.FS
 
+const (
+	AssetsDirEnv     = "COLORMANCER_ASSETS"
+	AssetArchivesEnv = "COLORMANCER_ARCHIVES"
+)
+
 func main() {
-	InitAudio()
-	go PlayBackgroundMusic()
-	Run(0)
+	assetsDir := flag.String("assets", defaultAssetsDir(), "directory whose files override the built-in assets (env "+AssetsDirEnv+")")
+	archives := flag.String("archives", os.Getenv(AssetArchivesEnv), "zip archives with fallback assets, separated by '"+string(os.PathListSeparator)+"' (env "+AssetArchivesEnv+")")
+	watch := flag.Bool("watch", false, "reload levels and assets from disk when they change")
+	seed := flag.Uint64("seed", 0, "seed for the random number generator, 0 picks one")
+	logLevel := flag.String("log-level", "info", "lowest level logged: debug, info, warn or error")
+	logFile := flag.String("log", "", "file to log to instead of stderr")
+	validate := flag.Bool("validate", false, "check that every button and exit of every level can be reached, then exit")
+	inspect := flag.String("inspect", "", "serve the debug inspector on this localhost address, e.g. localhost:6060")
+	flag.StringVar(&savePath, "save", savePath, "file F5 saves the game to and F9 loads it from")
+	flag.Parse()
+
+	embedded, err := fs.Sub(content, "assets")
+	if err != nil {
+		panic(err)
+	}
+	assets, err := engine.LayeredAssets(*assetsDir, embedded, filepath.SplitList(*archives)...)
+	if err != nil {
+		panic(err)
+	}
+	w := engine.GetInstance().UseAssets(assets)
+	logging := engine.LogConfig{Repeat: time.Second}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(*logLevel)); err != nil {
+		panic(err)
+	}
+	logging.Level = level
+	if *logFile != "" {
+		file, err := os.OpenFile(*logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			panic(err)
+		}
+		defer file.Close()
+		logging.Output = file
+	}
+	w.ConfigureLogging(logging)
+	if *seed != 0 {
+		w.RNG.Reseed(*seed)
+	}
+	slog.Info("Starting", "seed", w.RNG.Seed())
+	if *watch {
+		w.Assets.Watch(time.Second / 2)
+	}
+	if *validate {
+		trackTiles(w)
+		if err := validateLevels(w); err != nil {
+			slog.Error("Levels are not valid", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("All levels are valid")
+		return
+	}
+	if *inspect != "" {
+		if _, err := w.ServeInspector(*inspect); err != nil {
+			slog.Error("Failed starting inspector", "addr", *inspect, "error", err)
+		}
+	}
+	w.InitWindow("Colormancer", 800, 480)
+	InitAudio(w)
+	engine.FollowParent(w, PositionComponent.Offset)
+	trackTiles(w)
+	registerCommands(w)
+	w.PushScene(&LevelScene{Level: 0})
+	if err := w.Simulate(); err != nil {
+		slog.Error("Game stopped", "error", err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+}
+
+// defaultAssetsDir is the environment override if set, otherwise the
+// directory of the binary, so a levels/level_3.txt dropped next to it
+// replaces the built-in level.
+func defaultAssetsDir() string {
+	if dir := strings.TrimSpace(os.Getenv(AssetsDirEnv)); dir != "" {
+		return dir
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return filepath.Dir(executable)
 }
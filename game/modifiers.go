@@ -4,4 +4,5 @@ const (
 	OpenDoorModifier   = "Open"
 	ClosedDoorModifier = "Closed"
 	ColorModifier      = "Color"
+	BarrierModifier    = "Barrier"
 )
@@ -19,6 +19,8 @@ const (
 	CyanSummon      = '0'
 	RedSummon       = '1'
 	YellowSummon    = '2'
+	Barrier         = '+'
+	Box             = '$'
 )
 
 type Color struct {
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/lakrsv/parkour-engine/engine"
+)
+
+// savePath is where F5 saves the game and F9 loads it from.
+var savePath = defaultSavePath()
+
+func defaultSavePath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "colormancer.sav"
+	}
+	return filepath.Join(dir, "colormancer", "colormancer.sav")
+}
+
+func saveGame(w *engine.World) error {
+	snapshot, err := w.Snapshot()
+	if err != nil {
+		return err
+	}
+	data, err := snapshot.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(savePath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(savePath, data, 0o644)
+}
+
+func loadGame() (*engine.Snapshot, error) {
+	data, err := os.ReadFile(savePath)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &engine.Snapshot{}
+	if err := snapshot.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// checkpointLevel returns the level a save was made on.
+func checkpointLevel(snapshot *engine.Snapshot) int {
+	for _, resource := range snapshot.Resources {
+		if level, ok := resource.(Level); ok {
+			return level.Level
+		}
+	}
+	return 0
+}
@@ -1,9 +1,8 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"log/slog"
-	"math"
 	"reflect"
 	"time"
 
@@ -15,28 +14,38 @@ import (
 
 type RenderSystem struct {
 	palette RunePalette
+	font    *engine.Asset[*ttf.Font]
+}
+
+func (s *RenderSystem) Close() error {
+	s.font.Release()
+	return nil
 }
 
 func (s *RenderSystem) Initialize(w *engine.World) error {
-	cursor.Hide()
-	for range 64 {
-		fmt.Println()
+	font, err := w.Assets.Font("fonts/consolas.ttf", 16)
+	if err != nil {
+		return err
 	}
+	s.font = font
+	cursor.Hide()
 	return nil
 }
 
 func (s *RenderSystem) Update(w *engine.World) error {
-	grid := reflect.ValueOf(w.GetUniqueComponent(reflect.TypeOf(GridComponent{}))).Interface().(GridComponent)
-	level := reflect.ValueOf(w.GetUniqueComponent(reflect.TypeOf(LevelComponent{}))).Interface().(LevelComponent)
+	tilemap, err := engine.ResourceMut[engine.Tilemap](w)
+	if err != nil {
+		return err
+	}
+	level, err := engine.Resource[Level](w)
+	if err != nil {
+		return err
+	}
 
-	var font *ttf.Font
 	var surface *sdl.Surface
 
 	surface, _ = w.Window.GetSurface()
-
-	// Load the font for our text
-	font, _ = ttf.OpenFont("./assets/fonts/consolas.ttf", 16)
-	defer font.Close()
+	font := s.font.Get()
 
 	// Render header text
 	for i, headerLine := range level.Header {
@@ -49,28 +58,26 @@ func (s *RenderSystem) Update(w *engine.World) error {
 	headerHeight := len(level.Header) * 16
 
 	// Track current x position for each row
-	currentX := make([]int32, grid.Height)
-	for y := range grid.Height {
+	currentX := make([]int32, tilemap.Height)
+	for y := range tilemap.Height {
 		currentX[y] = 0
-		for x := range grid.Width {
-			entity := grid.EffectEntities[grid.GetCell(x, y)]
-			if _, ok := w.GetEntityComponent(entity, reflect.TypeOf(RenderComponent{})); !ok {
-				entity = math.MaxUint32
-			}
-			if entity == math.MaxUint32 {
-				entity = grid.ForegroundEntities[grid.GetCell(x, y)]
-				if _, ok := w.GetEntityComponent(entity, reflect.TypeOf(RenderComponent{})); !ok {
-					entity = math.MaxUint32
+		for x := range tilemap.Width {
+			var entity uint32
+			found := false
+			for _, layer := range []string{EffectLayer, ForegroundLayer, BackgroundLayer} {
+				if at, ok := tilemap.At(layer, engine.Cell{X: x, Y: y}); ok && w.HasComponent(at, reflect.TypeOf(RenderComponent{})) {
+					entity, found = at, true
+					break
 				}
 			}
-			if entity == math.MaxUint32 {
-				entity = grid.BackgroundEntities[grid.GetCell(x, y)]
+			if !found {
+				continue
 			}
 			if component, ok := w.GetEntityComponent(entity, reflect.TypeOf(RenderComponent{})); ok {
 				render := reflect.ValueOf(component).Interface().(RenderComponent)
 				var textColor sdl.Color
 				if colorComponent, ok := w.GetEntityComponent(entity, reflect.TypeOf(ColorComponent{})); ok {
-					c := reflect.ValueOf(colorComponent).Interface().(ColorComponent).color
+					c := reflect.ValueOf(colorComponent).Interface().(ColorComponent).Color
 					textColor = sdl.Color{R: c.R, G: c.G, B: c.B, A: 255}
 				} else {
 					c := s.palette.GetColor(render.Character)
@@ -88,20 +95,63 @@ func (s *RenderSystem) Update(w *engine.World) error {
 	uiTexts := []string{
 		"WASD = Move",
 		"E = Summon",
+		"Z = Undo",
+		"Y = Redo",
 		"R = Restart",
+		"P = Pause",
 		"Q = Quit",
 	}
+	if paused, err := engine.Resource[Paused](w); err == nil && paused.Paused {
+		uiTexts = append([]string{"PAUSED"}, uiTexts...)
+	}
 
 	// Use a new variable for UI text x-position
 	for i, txt := range uiTexts {
 		text, _ := font.RenderUTF8Blended(txt, sdl.Color{R: 255, G: 255, B: 255, A: 255})
 		defer text.Free()
-		_ = text.Blit(nil, surface, &sdl.Rect{X: 0, Y: int32(grid.Height*16 + headerHeight + i*16), W: 0, H: 0})
+		_ = text.Blit(nil, surface, &sdl.Rect{X: 0, Y: int32(tilemap.Height*16 + headerHeight + i*16), W: 0, H: 0})
+	}
+
+	return nil
+}
+
+// PauseSystem toggles Paused with P and keeps the Gameplay systems from
+// running while the game is paused or the console is open.
+type PauseSystem struct {
+	Gameplay []engine.System
+}
+
+func (s *PauseSystem) Initialize(world *engine.World) error {
+	engine.InsertResource(world, Paused{})
+	world.RunIf(s, engine.ConsoleClosed)
+	for _, system := range s.Gameplay {
+		world.RunIf(system, notPaused).RunIf(system, engine.ConsoleClosed)
 	}
+	return nil
+}
 
+func (s *PauseSystem) Update(world *engine.World) error {
+	input, err := engine.Resource[engine.Input](world)
+	if err != nil {
+		return err
+	}
+	paused, err := engine.ResourceMut[Paused](world)
+	if err != nil {
+		return err
+	}
+	if input.KeyPressed(sdl.K_p) {
+		paused.Paused = !paused.Paused
+	} else if paused.Paused && input.KeyPressed(sdl.K_q) {
+		world.Stop()
+	}
 	return nil
 }
 
+func notPaused(world *engine.World) bool {
+	paused, err := engine.Resource[Paused](world)
+	return err != nil || !paused.Paused
+}
+
 type PlayerInputSystem struct {
 	group *engine.Group
 }
@@ -115,21 +165,44 @@ func (p *PlayerInputSystem) Initialize(world *engine.World) error {
 }
 
 func (p *PlayerInputSystem) Update(world *engine.World) error {
-	input := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(engine.InputComponent{}))).Interface().(engine.InputComponent)
+	input, err := engine.Resource[engine.Input](world)
+	if err != nil {
+		return err
+	}
 	if input.KeyPressed(sdl.K_q) {
-		if err := world.Close(); err != nil {
-			panic(err)
-		}
+		world.Stop()
 		return nil
 	}
 	if input.KeyPressed(sdl.K_r) {
-		currentLevel := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(LevelComponent{}))).Interface().(LevelComponent).Level
-		if err := world.Close(); err != nil {
-			panic(err)
+		currentLevel, err := engine.Resource[Level](world)
+		if err != nil {
+			return err
 		}
-		Run(currentLevel)
+		world.ReplaceScene(&LevelScene{Level: currentLevel.Level})
+		return nil
+	}
+	if input.KeyPressed(sdl.K_z) {
+		world.History().Undo()
+		return nil
+	}
+	if input.KeyPressed(sdl.K_y) {
+		world.History().Redo()
 		return nil
 	}
+	if input.KeyPressed(sdl.K_F5) {
+		if err := saveGame(world); err != nil {
+			slog.Error("Failed saving game", "path", savePath, "error", err)
+		}
+	}
+	if input.KeyPressed(sdl.K_F9) {
+		snapshot, err := loadGame()
+		if err != nil {
+			slog.Error("Failed loading game", "path", savePath, "error", err)
+		} else {
+			world.ReplaceScene(&LevelScene{Level: checkpointLevel(snapshot), Checkpoint: snapshot})
+			return nil
+		}
+	}
 
 	// Movement
 	var x, y int
@@ -144,12 +217,14 @@ func (p *PlayerInputSystem) Update(world *engine.World) error {
 		x = -1
 	}
 	if x != 0 || y != 0 {
+		world.History().BeginTurn()
 		for _, entity := range p.group.GetEntities() {
 			world.ReplaceComponent(entity, MoveComponent{x, y})
 		}
 	}
 
 	if input.KeyPressed(sdl.K_e) {
+		world.History().BeginTurn()
 		for _, entity := range p.group.GetEntities() {
 			if _, ok := world.GetEntityComponent(entity, reflect.TypeOf(CreateSummonComponent{})); !ok {
 				world.AddComponent(entity, CreateSummonComponent{})
@@ -173,7 +248,6 @@ func (m *MoveSystem) Initialize(world *engine.World) error {
 }
 
 func (m *MoveSystem) Update(world *engine.World) error {
-	grid := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(GridComponent{}))).Interface().(GridComponent)
 	for _, entity := range m.group.GetEntities() {
 		if moveComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(MoveComponent{})); ok {
 			move := reflect.ValueOf(moveComponent).Interface().(MoveComponent)
@@ -181,48 +255,20 @@ func (m *MoveSystem) Update(world *engine.World) error {
 				continue
 			}
 
-			if positionComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(PositionComponent{})); ok {
-				position := reflect.ValueOf(positionComponent).Interface().(PositionComponent)
-				newPosition := PositionComponent{position.X + move.X, position.Y + move.Y}
-
-				newCell := grid.GetCell(newPosition.X, newPosition.Y)
-
-				newCellEntity := grid.BackgroundEntities[newCell]
-				if _, ok := world.GetEntityComponent(newCellEntity, reflect.TypeOf(ObstacleComponent{})); ok {
-					// Can not walk
-					world.ReplaceComponent(entity, MoveComponent{0, 0})
-					if _, ok := world.GetEntityComponent(entity, reflect.TypeOf(FacingComponent{})); ok {
-						world.ReplaceComponent(entity, FacingComponent{0, 0})
-					}
-					continue
-				}
-				// Something is already there in the foreground
-				if grid.ForegroundEntities[newCell] != math.MaxUint32 {
-					world.ReplaceComponent(entity, MoveComponent{0, 0})
-					if _, ok := world.GetEntityComponent(entity, reflect.TypeOf(FacingComponent{})); ok {
-						world.ReplaceComponent(entity, FacingComponent(move))
-					}
-					continue
-				}
-
-				oldCell := grid.GetCell(position.X, position.Y)
-				if grid.ForegroundEntities[oldCell] != entity {
-					slog.Error(
-						"Entity in foreground did not match expected entity!",
-						"expected", entity,
-						"actual", grid.ForegroundEntities[oldCell],
-					)
-				}
-				grid.ForegroundEntities[oldCell] = math.MaxUint32
-				grid.ForegroundEntities[newCell] = entity
-
-				world.ReplaceComponent(entity, MoveComponent{0, 0})
-				if _, ok := world.GetEntityComponent(entity, reflect.TypeOf(FacingComponent{})); ok {
-					world.ReplaceComponent(entity, FacingComponent(move))
-				}
-				world.ReplaceComponent(entity, newPosition)
-				playWalkSound()
+			world.ReplaceComponent(entity, MoveComponent{0, 0})
+			err := world.Move(entity, engine.Cell{X: move.X, Y: move.Y})
+			facing := FacingComponent(move)
+			if err != nil && !errors.Is(err, engine.ErrCellOccupied) {
+				// Can not walk
+				facing = FacingComponent{0, 0}
+			}
+			if _, ok := world.GetEntityComponent(entity, reflect.TypeOf(FacingComponent{})); ok {
+				world.ReplaceComponent(entity, facing)
+			}
+			if err != nil {
+				continue
 			}
+			world.CreateEntity(engine.PlaySound{Name: WalkSound})
 		}
 	}
 	return nil
@@ -248,7 +294,14 @@ func (s *DeferDoorRenderSystem) Initialize(world *engine.World) error {
 }
 
 func (s *DeferDoorRenderSystem) Update(world *engine.World) error {
-	grid := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(GridComponent{}))).Interface().(GridComponent)
+	tilemap, err := engine.ResourceMut[engine.Tilemap](world)
+	if err != nil {
+		return err
+	}
+	obstacle := func(cell engine.Cell) bool {
+		neighbour, ok := tilemap.At(BackgroundLayer, cell)
+		return ok && world.HasComponent(neighbour, reflect.TypeOf(ObstacleComponent{}))
+	}
 
 	for _, entity := range s.group.GetEntities() {
 		positionComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(PositionComponent{}))
@@ -256,20 +309,16 @@ func (s *DeferDoorRenderSystem) Update(world *engine.World) error {
 			continue
 		}
 
-		position := reflect.ValueOf(positionComponent).Interface().(PositionComponent)
-		leftNeighbour := grid.BackgroundEntities[grid.GetCell(position.X-1, position.Y)]
-		rightNeighbour := grid.BackgroundEntities[grid.GetCell(position.X+1, position.Y)]
-		upNeighbour := grid.BackgroundEntities[grid.GetCell(position.X, position.Y-1)]
-		downNeighbour := grid.BackgroundEntities[grid.GetCell(position.X, position.Y+1)]
+		cell := reflect.ValueOf(positionComponent).Interface().(PositionComponent).Cell()
 
-		if _, ok := world.GetEntityComponent(upNeighbour, reflect.TypeOf(ObstacleComponent{})); ok {
-			if _, ok := world.GetEntityComponent(downNeighbour, reflect.TypeOf(ObstacleComponent{})); ok {
+		if obstacle(cell.Add(engine.Cell{X: 0, Y: -1})) {
+			if obstacle(cell.Add(engine.Cell{X: 0, Y: 1})) {
 				// Vertical Door
 				world.AddComponent(entity, RenderComponent{Character: DoorVertical})
 				continue
 			}
-		} else if _, ok := world.GetEntityComponent(leftNeighbour, reflect.TypeOf(ObstacleComponent{})); ok {
-			if _, ok := world.GetEntityComponent(rightNeighbour, reflect.TypeOf(ObstacleComponent{})); ok {
+		} else if obstacle(cell.Add(engine.Cell{X: -1, Y: 0})) {
+			if obstacle(cell.Add(engine.Cell{X: 1, Y: 0})) {
 				// Horizontal Door
 				world.AddComponent(entity, RenderComponent{Character: DoorHorizontal})
 				continue
@@ -338,15 +387,20 @@ func (t *TriggerSystem) Initialize(world *engine.World) error {
 }
 
 func (t *TriggerSystem) Update(world *engine.World) error {
-	grid := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(GridComponent{}))).Interface().(GridComponent)
+	tilemap, err := engine.ResourceMut[engine.Tilemap](world)
+	if err != nil {
+		return err
+	}
 	for _, entity := range t.moving.GetEntities() {
 		if positionComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(PositionComponent{})); ok {
-			position := reflect.ValueOf(positionComponent).Interface().(PositionComponent)
-			cellPos := grid.GetCell(position.X, position.Y)
-			if grid.ForegroundEntities[cellPos] != entity {
+			cell := reflect.ValueOf(positionComponent).Interface().(PositionComponent).Cell()
+			if foregroundEntity, ok := tilemap.At(ForegroundLayer, cell); !ok || foregroundEntity != entity {
+				continue
+			}
+			backgroundEntity, ok := tilemap.At(BackgroundLayer, cell)
+			if !ok {
 				continue
 			}
-			backgroundEntity := grid.BackgroundEntities[cellPos]
 			if triggerComponent, ok := world.GetEntityComponent(backgroundEntity, reflect.TypeOf(TriggerComponent{})); ok {
 
 				// Check if color match
@@ -355,7 +409,7 @@ func (t *TriggerSystem) Update(world *engine.World) error {
 						triggerColor := reflect.ValueOf(triggerColorComponent).Interface().(ColorComponent)
 						entityTriggerInteractColor := reflect.ValueOf(entityTriggerInteractColorComponent).Interface().(InteractsWithTriggersComponent)
 
-						if triggerColor.color != entityTriggerInteractColor.color {
+						if triggerColor.Color != entityTriggerInteractColor.Color {
 							continue
 						}
 					}
@@ -370,13 +424,23 @@ func (t *TriggerSystem) Update(world *engine.World) error {
 							}
 							if triggeredComponent, ok := world.GetEntityComponent(triggeredEntity, reflect.TypeOf(TriggeredComponent{})); ok {
 								triggered := reflect.ValueOf(triggeredComponent).Interface().(TriggeredComponent)
-								triggered.Action(triggeredEntity, world)
+								action, ok := triggerActions[triggered.Action]
+								if !ok {
+									slog.Error("Unknown trigger action", "action", triggered.Action)
+									continue
+								}
+								if err := action(triggeredEntity, world); err != nil {
+									return err
+								}
 								trigger.Triggered = true
 							}
 						}
 					}
 					world.ReplaceComponent(backgroundEntity, trigger)
 					world.ReplaceComponent(backgroundEntity, RenderComponent{Character: TriggeredButton})
+					if trigger.Symbol != Exit {
+						engine.Send(world, ButtonPressed{Symbol: trigger.Symbol})
+					}
 				}
 			}
 		}
@@ -384,13 +448,45 @@ func (t *TriggerSystem) Update(world *engine.World) error {
 	return nil
 }
 
+type LevelReloadSystem struct {
+	stopListening func()
+	reload        bool
+}
+
+func (s *LevelReloadSystem) Close() error {
+	if s.stopListening != nil {
+		s.stopListening()
+	}
+	return nil
+}
+
+func (s *LevelReloadSystem) Initialize(world *engine.World) error {
+	level, err := engine.Resource[Level](world)
+	if err != nil {
+		return err
+	}
+	path := levelPath(level.Level)
+	s.stopListening = world.Assets.OnReload(func(name string) {
+		if name == path || name == prefabsPath {
+			s.reload = true
+		}
+	})
+	return nil
+}
+
+func (s *LevelReloadSystem) Update(world *engine.World) error {
+	if !s.reload {
+		return nil
+	}
+	s.reload = false
+	return reloadLevel(world)
+}
+
 type DirectionIndicatorSystem struct {
-	facing                      *engine.Group
-	directionIndicatorsByEntity map[uint32]uint32
+	facing *engine.Group
 }
 
 func (s *DirectionIndicatorSystem) Initialize(world *engine.World) error {
-	s.directionIndicatorsByEntity = make(map[uint32]uint32)
 	s.facing = world.GetGroup(&engine.AllOfMatcher{Matchers: []engine.Matcher{
 		&engine.AllOfComponentMatcher{Components: []reflect.Type{
 			reflect.TypeOf(FacingComponent{}),
@@ -401,42 +497,39 @@ func (s *DirectionIndicatorSystem) Initialize(world *engine.World) error {
 }
 
 func (s *DirectionIndicatorSystem) Update(world *engine.World) error {
+	tilemap, err := engine.ResourceMut[engine.Tilemap](world)
+	if err != nil {
+		return err
+	}
 	for _, entity := range s.facing.GetEntities() {
 		if facingComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(FacingComponent{})); ok {
 			facing := reflect.ValueOf(facingComponent).Interface().(FacingComponent)
+			directionIndicator, ok := directionIndicatorOf(world, entity)
 			if facing.X == 0 && facing.Y == 0 {
-				if directionIndicatorEntity, ok := s.directionIndicatorsByEntity[entity]; ok {
-					world.RemoveComponent(directionIndicatorEntity, reflect.TypeOf(RenderComponent{}))
+				if ok {
+					world.RemoveComponent(directionIndicator, reflect.TypeOf(RenderComponent{}))
 				}
 				continue
 			}
-			if positionComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(PositionComponent{})); ok {
-				position := reflect.ValueOf(positionComponent).Interface().(PositionComponent)
-				directionIndicator, ok := s.directionIndicatorsByEntity[entity]
-				if !ok {
-					directionIndicator = world.CreateEntity()
-					s.directionIndicatorsByEntity[entity] = directionIndicator
-				}
 
-				grid := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(GridComponent{}))).Interface().(GridComponent)
-				if directionIndicatorPositionComponent, ok := world.GetEntityComponent(directionIndicator, reflect.TypeOf(PositionComponent{})); ok {
-					indicatorPosition := reflect.ValueOf(directionIndicatorPositionComponent).Interface().(PositionComponent)
-					indicatorCell := grid.GetCell(indicatorPosition.X, indicatorPosition.Y)
-					if grid.EffectEntities[indicatorCell] != math.MaxUint32 {
-						grid.EffectEntities[indicatorCell] = math.MaxUint32
-					}
-				}
-				newIndicatorPosition := PositionComponent{position.X + facing.X, position.Y + facing.Y}
-				grid.EffectEntities[grid.GetCell(newIndicatorPosition.X, newIndicatorPosition.Y)] = directionIndicator
-				world.ReplaceComponent(directionIndicator, newIndicatorPosition)
+			offset := engine.Relative[PositionComponent]{Offset: PositionComponent{X: facing.X, Y: facing.Y}}
+			if !ok {
+				directionIndicator = world.CreateEntity(DirectionIndicatorComponent{}, engine.TileLayer(EffectLayer), offset)
+				world.SetParent(directionIndicator, entity)
+			} else if relative, _ := world.GetEntityComponent(directionIndicator, reflect.TypeOf(offset)); relative != offset {
+				world.ReplaceComponent(directionIndicator, offset)
+			}
+
+			if indicatorPositionComponent, ok := world.GetEntityComponent(directionIndicator, reflect.TypeOf(PositionComponent{})); ok {
+				indicatorCell := reflect.ValueOf(indicatorPositionComponent).Interface().(PositionComponent).Cell()
 
-				if grid.ForegroundEntities[grid.GetCell(newIndicatorPosition.X, newIndicatorPosition.Y)] != math.MaxUint32 {
+				if _, ok := tilemap.At(ForegroundLayer, indicatorCell); ok {
 					world.RemoveComponent(directionIndicator, reflect.TypeOf(RenderComponent{}))
 					continue
 				}
 
-				backgroundEntity := grid.BackgroundEntities[grid.GetCell(newIndicatorPosition.X, newIndicatorPosition.Y)]
-				if _, ok := world.GetEntityComponent(backgroundEntity, reflect.TypeOf(FloorComponent{})); !ok {
+				backgroundEntity, ok := tilemap.At(BackgroundLayer, indicatorCell)
+				if !ok || !world.HasComponent(backgroundEntity, reflect.TypeOf(FloorComponent{})) {
 					world.RemoveComponent(directionIndicator, reflect.TypeOf(RenderComponent{}))
 					continue
 				}
@@ -464,6 +557,16 @@ func (s *DirectionIndicatorSystem) Update(world *engine.World) error {
 	return nil
 }
 
+// directionIndicatorOf returns the direction indicator attached to entity.
+func directionIndicatorOf(world *engine.World, entity uint32) (uint32, bool) {
+	for _, child := range world.GetChildren(entity) {
+		if _, ok := world.GetEntityComponent(child, reflect.TypeOf(DirectionIndicatorComponent{})); ok {
+			return child, true
+		}
+	}
+	return 0, false
+}
+
 type SummonInputSystem struct {
 	timePassed      time.Duration
 	updateFrequency time.Duration
@@ -480,7 +583,11 @@ func (s *SummonInputSystem) Initialize(world *engine.World) error {
 }
 
 func (s *SummonInputSystem) Update(world *engine.World) error {
-	s.timePassed += world.Time.DeltaTime
+	clock, err := engine.Resource[engine.Time](world)
+	if err != nil {
+		return err
+	}
+	s.timePassed += clock.DeltaTime
 	if s.timePassed >= s.updateFrequency {
 		s.timePassed = 0
 		for _, entity := range s.group.GetEntities() {
@@ -519,22 +626,26 @@ func (s *CreateSummonSystem) Update(world *engine.World) error {
 				position := reflect.ValueOf(positionComponent).Interface().(PositionComponent)
 				summonPosition := PositionComponent{position.X + facing.X, position.Y + facing.Y}
 
-				grid := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(GridComponent{}))).Interface().(GridComponent)
-				summonCell := grid.GetCell(summonPosition.X, summonPosition.Y)
-				if grid.ForegroundEntities[summonCell] != math.MaxUint32 {
-					world.RemoveComponent(entity, reflect.TypeOf(CreateSummonComponent{}))
-					continue
+				tilemap, err := engine.ResourceMut[engine.Tilemap](world)
+				if err != nil {
+					return err
 				}
-				if _, ok := world.GetEntityComponent(grid.BackgroundEntities[summonCell], reflect.TypeOf(ObstacleComponent{})); ok {
+				if _, ok := tilemap.At(ForegroundLayer, summonPosition.Cell()); ok || blocked(world, tilemap, summonPosition.Cell()) {
 					world.RemoveComponent(entity, reflect.TypeOf(CreateSummonComponent{}))
 					continue
 				}
 
 				if summonComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(SummonComponent{})); ok {
 					summon := reflect.ValueOf(summonComponent).Interface().(SummonComponent)
-					grid.ForegroundEntities[summonCell] = world.CreateEntity(
-						SummonBlueprint(summonPosition.X, summonPosition.Y, facing.X, facing.Y, summon.color)...,
-					)
+					if _, err := world.Spawn("Summon", engine.Overrides{
+						"Position":              PositionComponent{X: summonPosition.X, Y: summonPosition.Y},
+						"SummonInput":           SummonInputComponent{X: facing.X, Y: facing.Y},
+						"Color":                 ColorComponent(summon),
+						"InteractsWithTriggers": InteractsWithTriggersComponent(summon),
+						"engine.Collider":       colliderOf("Summon", summon.Color),
+					}); err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -555,19 +666,24 @@ func (s *SummonPickupSystem) Initialize(world *engine.World) error {
 }
 
 func (s *SummonPickupSystem) Update(world *engine.World) error {
-	grid := reflect.ValueOf(world.GetUniqueComponent(reflect.TypeOf(GridComponent{}))).Interface().(GridComponent)
+	tilemap, err := engine.ResourceMut[engine.Tilemap](world)
+	if err != nil {
+		return err
+	}
 	for _, entity := range s.group.GetEntities() {
 		if positionComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(PositionComponent{})); ok {
-			position := reflect.ValueOf(positionComponent).Interface().(PositionComponent)
-			entityCell := grid.GetCell(position.X, position.Y)
-			backgroundEntity := grid.BackgroundEntities[entityCell]
+			cell := reflect.ValueOf(positionComponent).Interface().(PositionComponent).Cell()
+			backgroundEntity, ok := tilemap.At(BackgroundLayer, cell)
+			if !ok {
+				continue
+			}
 			if summonPickupComponent, ok := world.GetEntityComponent(backgroundEntity, reflect.TypeOf(SummonPickupComponent{})); ok {
 				summonPickup := reflect.ValueOf(summonPickupComponent).Interface().(SummonPickupComponent)
 				if summonComponent, ok := world.GetEntityComponent(entity, reflect.TypeOf(SummonComponent{})); ok {
 					summon := reflect.ValueOf(summonComponent).Interface().(SummonComponent)
-					if summonPickup.color != summon.color {
-						playPickupSound()
+					if summonPickup.Color != summon.Color {
 						world.ReplaceComponent(entity, SummonComponent(summonPickup))
+						engine.Send(world, ColorPickedUp{Entity: entity, Color: summonPickup.Color})
 					}
 				}
 			}
@@ -575,3 +691,45 @@ func (s *SummonPickupSystem) Update(world *engine.World) error {
 	}
 	return nil
 }
+
+// SoundEffectSystem plays the sounds of gameplay events.
+type SoundEffectSystem struct {
+	doorsOpened    engine.EventReader[DoorOpened]
+	doorsClosed    engine.EventReader[DoorClosed]
+	colorsPickedUp engine.EventReader[ColorPickedUp]
+	levels         engine.EventReader[LevelCompleted]
+	collisions     engine.EventReader[engine.Collision]
+}
+
+func (s *SoundEffectSystem) Update(world *engine.World) error {
+	for range engine.Read(world, &s.doorsOpened) {
+		world.CreateEntity(engine.PlaySound{Name: DoorOpenSound})
+	}
+	for range engine.Read(world, &s.doorsClosed) {
+		world.CreateEntity(engine.PlaySound{Name: DoorOpenSound})
+	}
+	for range engine.Read(world, &s.colorsPickedUp) {
+		world.CreateEntity(engine.PlaySound{Name: PickupColorSound})
+	}
+	for range engine.Read(world, &s.levels) {
+		world.CreateEntity(engine.PlaySound{Name: GoalSound})
+	}
+	for _, collision := range engine.Read(world, &s.collisions) {
+		if world.HasComponent(collision.Entity, reflect.TypeOf(PlayerInputComponent{})) {
+			world.CreateEntity(engine.PlaySound{Name: BumpSound})
+		}
+	}
+	return nil
+}
+
+// LevelProgressSystem moves on to the next level when one is completed.
+type LevelProgressSystem struct {
+	levels engine.EventReader[LevelCompleted]
+}
+
+func (s *LevelProgressSystem) Update(world *engine.World) error {
+	for _, completed := range engine.Read(world, &s.levels) {
+		world.ReplaceScene(&LevelScene{Level: completed.Level + 1})
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/lakrsv/parkour-engine/engine"
+	"github.com/lakrsv/parkour-engine/engine/pathfinding"
+)
+
+var ErrUnreachable = errors.New("can not be reached")
+
+var triggersMatcher = &engine.AllOfComponentMatcher{Components: []reflect.Type{
+	reflect.TypeOf(TriggerComponent{}),
+	reflect.TypeOf(PositionComponent{}),
+}}
+
+var directions = []engine.Cell{{X: 0, Y: -1}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: -1, Y: 0}}
+
+// levelState is the sorted symbols of the buttons pressed, the color the
+// player summons and a cell of the area the player can walk in.
+type levelState struct {
+	pressed string
+	color   Color
+	cell    engine.Cell
+}
+
+// waitingSummon is a summon in cell that keeps trying to step in direction.
+type waitingSummon struct {
+	cell, direction engine.Cell
+	color           Color
+}
+
+type levelValidation struct {
+	width, height int
+	interacts     Color
+	// blocked holds the cells the player can not step into, summonBlocked the
+	// ones summons of each color can not.
+	blocked       map[engine.Cell]bool
+	summonBlocked map[Color]map[engine.Cell]bool
+	// The level's cells by what is in their background.
+	doors   map[engine.Cell]TriggeredComponent
+	buttons map[engine.Cell]TriggerComponent
+	colors  map[engine.Cell]Color
+	pickups map[engine.Cell]Color
+	// reached holds the cells the player or a summon can get to, pressed the
+	// ones with a button that can be pressed.
+	reached map[engine.Cell]bool
+	pressed map[engine.Cell]bool
+	// waiting holds the summons stopped at doors in any state so far.
+	waiting []waitingSummon
+}
+
+// validateLevel checks that the player or a summon can get to every button of
+// the loaded level, and that its exit can be pressed unless it is the last
+// level. It goes through the states the level can get into as the player and
+// summons press the buttons of their color they step on. Summons walk
+// straight on and wait at closed doors. Boxes are not pushed.
+func validateLevel(w *engine.World) error {
+	tilemap, err := engine.Resource[engine.Tilemap](w)
+	if err != nil {
+		return err
+	}
+	player, ok := w.FindByName(PlayerName)
+	if !ok {
+		return errors.New("the level has no player")
+	}
+	start, _ := tilemap.Cell(player)
+	interacts, _ := w.GetEntityComponent(player, reflect.TypeOf(InteractsWithTriggersComponent{}))
+	summon, _ := w.GetEntityComponent(player, reflect.TypeOf(SummonComponent{}))
+	v := &levelValidation{
+		width:     tilemap.Width,
+		height:    tilemap.Height,
+		interacts: interacts.(InteractsWithTriggersComponent).Color,
+		doors:     map[engine.Cell]TriggeredComponent{},
+		buttons:   map[engine.Cell]TriggerComponent{},
+		colors:    map[engine.Cell]Color{},
+		pickups:   map[engine.Cell]Color{},
+		reached:   map[engine.Cell]bool{},
+		pressed:   map[engine.Cell]bool{},
+	}
+	for y := range tilemap.Height {
+		for x := range tilemap.Width {
+			cell := engine.Cell{X: x, Y: y}
+			background, ok := tilemap.At(BackgroundLayer, cell)
+			if !ok {
+				continue
+			}
+			if triggered, ok := w.GetEntityComponent(background, reflect.TypeOf(TriggeredComponent{})); ok {
+				if action := triggered.(TriggeredComponent).Action; action == OpenDoorAction || action == CloseDoorAction {
+					v.doors[cell] = triggered.(TriggeredComponent)
+					continue
+				}
+			}
+			if trigger, ok := w.GetEntityComponent(background, reflect.TypeOf(TriggerComponent{})); ok {
+				v.buttons[cell] = trigger.(TriggerComponent)
+			}
+			if color, ok := w.GetEntityComponent(background, reflect.TypeOf(ColorComponent{})); ok {
+				v.colors[cell] = color.(ColorComponent).Color
+			}
+			if pickup, ok := w.GetEntityComponent(background, reflect.TypeOf(SummonPickupComponent{})); ok {
+				v.pickups[cell] = pickup.(SummonPickupComponent).Color
+			}
+		}
+	}
+
+	// The summons are tried in a copy of the world, away from the player.
+	clone := w.Clone()
+	v.blocked = v.blockedFor(clone, player)
+	clone.DeleteEntity(player)
+	v.summonBlocked = map[Color]map[engine.Cell]bool{}
+	for _, color := range append(slices.Collect(maps.Values(v.pickups)), summon.(SummonComponent).Color) {
+		if _, ok := v.summonBlocked[color]; !ok {
+			probe := clone.CreateEntity(engine.TileLayer(ForegroundLayer), colliderOf("Summon", color))
+			v.summonBlocked[color] = v.blockedFor(clone, probe)
+			clone.DeleteEntity(probe)
+		}
+	}
+
+	seen := map[levelState]bool{}
+	queue := []levelState{{color: summon.(SummonComponent).Color, cell: start}}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		area := v.playerGrid(state.pressed).Reachable(state.cell)
+		key := state
+		key.cell = slices.MinFunc(area, func(a, b engine.Cell) int {
+			return cmp.Or(cmp.Compare(a.Y, b.Y), cmp.Compare(a.X, b.X))
+		})
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if states := v.walk(state, area, v.waiting); states != nil {
+			queue = append(queue, states...)
+			continue
+		}
+		for _, cell := range area {
+			v.reached[cell] = true
+			if color, ok := v.pickups[cell]; ok && color != state.color {
+				queue = append(queue, levelState{pressed: state.pressed, color: color, cell: cell})
+			}
+			for _, direction := range directions {
+				next := cell.Add(direction)
+				if v.presses(state.pressed, next, v.interacts) && v.grid(state.pressed, v.blocked).Walkable(next) {
+					v.reached[next] = true
+					queue = append(queue, levelState{pressed: v.press(state.pressed, next, v.interacts), color: state.color, cell: next})
+				}
+				if !v.grid(state.pressed, v.summonBlocked[state.color]).Walkable(next) {
+					continue
+				}
+				sent := waitingSummon{cell: cell, direction: direction, color: state.color}
+				queue = append(queue, v.walk(state, area, []waitingSummon{sent})...)
+			}
+		}
+	}
+
+	// The exit of the last level leads nowhere.
+	level, err := engine.Resource[Level](w)
+	if err != nil {
+		return err
+	}
+	_, err = fs.Stat(w.Assets, levelPath(level.Level+1))
+	last := err != nil
+
+	var errs []error
+	for _, entity := range w.GetGroup(triggersMatcher).GetEntities() {
+		position, _ := w.GetEntityComponent(entity, reflect.TypeOf(PositionComponent{}))
+		cell := position.(PositionComponent).Cell()
+		trigger, _ := w.GetEntityComponent(entity, reflect.TypeOf(TriggerComponent{}))
+		if symbol := trigger.(TriggerComponent).Symbol; symbol == Exit && !last && !v.pressed[cell] || symbol != Exit && !v.reached[cell] {
+			errs = append(errs, fmt.Errorf("%c at %d %d %w", trigger.(TriggerComponent).Symbol, cell.X, cell.Y, ErrUnreachable))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// blockedFor returns the cells entity can not step into in w, leaving the
+// doors to grid.
+func (v *levelValidation) blockedFor(w *engine.World, entity uint32) map[engine.Cell]bool {
+	blocked := map[engine.Cell]bool{}
+	for y := range v.height {
+		for x := range v.width {
+			cell := engine.Cell{X: x, Y: y}
+			err := w.CanOccupy(entity, cell)
+			if _, door := v.doors[cell]; door && errors.Is(err, ErrBlocked) {
+				continue
+			}
+			blocked[cell] = err != nil
+		}
+	}
+	return blocked
+}
+
+// grid is where an entity kept out of blocked can walk once the buttons with
+// the pressed symbols are pressed.
+func (v *levelValidation) grid(pressed string, blocked map[engine.Cell]bool) pathfinding.Grid {
+	return pathfinding.Grid{
+		Width:  v.width,
+		Height: v.height,
+		Walkable: func(cell engine.Cell) bool {
+			if door, ok := v.doors[cell]; ok && (door.Action == OpenDoorAction) != strings.ContainsRune(pressed, door.Symbol) {
+				return false
+			}
+			return cell.X >= 0 && cell.Y >= 0 && cell.X < v.width && cell.Y < v.height && !blocked[cell]
+		},
+	}
+}
+
+// playerGrid is where the player can walk once the buttons with the pressed
+// symbols are pressed, without pressing another on the way.
+func (v *levelValidation) playerGrid(pressed string) pathfinding.Grid {
+	grid := v.grid(pressed, v.blocked)
+	walkable := grid.Walkable
+	grid.Walkable = func(cell engine.Cell) bool {
+		return walkable(cell) && !v.presses(pressed, cell, v.interacts)
+	}
+	return grid
+}
+
+// presses reports whether an entity of color presses a button not yet pressed
+// when it steps into cell.
+func (v *levelValidation) presses(pressed string, cell engine.Cell, color Color) bool {
+	button, ok := v.buttons[cell]
+	if !ok || strings.ContainsRune(pressed, button.Symbol) {
+		return false
+	}
+	buttonColor, ok := v.colors[cell]
+	return !ok || buttonColor == color
+}
+
+// press presses the button in cell, if an entity of color presses it, and
+// returns the symbols pressed after it.
+func (v *levelValidation) press(pressed string, cell engine.Cell, color Color) string {
+	if !v.presses(pressed, cell, color) {
+		return pressed
+	}
+	v.pressed[cell] = true
+	symbols := []rune(pressed + string(v.buttons[cell].Symbol))
+	slices.Sort(symbols)
+	return string(symbols)
+}
+
+// walk moves summons on until each is stopped, and returns the states the
+// level can be in then, or nil if they pressed no button. area is where the
+// player can walk, following them.
+func (v *levelValidation) walk(state levelState, area []engine.Cell, summons []waitingSummon) []levelState {
+	summons = slices.Clone(summons)
+	pressed := state.pressed
+	for changed := true; changed; {
+		changed = false
+		for i, summon := range summons {
+			for next := summon.cell.Add(summon.direction); v.grid(pressed, v.summonBlocked[summon.color]).Walkable(next); next = next.Add(summon.direction) {
+				v.reached[next] = true
+				summons[i].cell = next
+				if after := v.press(pressed, next, summon.color); after != pressed {
+					pressed, changed = after, true
+					area, _ = spread(v.playerGrid(pressed), area)
+				}
+			}
+		}
+	}
+	for _, summon := range summons {
+		if _, ok := v.doors[summon.cell.Add(summon.direction)]; ok && !slices.Contains(v.waiting, summon) {
+			v.waiting = append(v.waiting, summon)
+		}
+	}
+	if pressed == state.pressed {
+		return nil
+	}
+	_, starts := spread(v.playerGrid(pressed), area)
+	states := make([]levelState, len(starts))
+	for i, cell := range starts {
+		states[i] = levelState{pressed: pressed, color: state.color, cell: cell}
+	}
+	return states
+}
+
+// spread returns the cells that can be reached from any of cells, and one of
+// cells for each area apart from the others.
+func spread(grid pathfinding.Grid, cells []engine.Cell) (area, starts []engine.Cell) {
+	seen := map[engine.Cell]bool{}
+	for _, cell := range cells {
+		if seen[cell] {
+			continue
+		}
+		starts = append(starts, cell)
+		for _, reached := range grid.Reachable(cell) {
+			if !seen[reached] {
+				seen[reached] = true
+				area = append(area, reached)
+			}
+		}
+	}
+	return area, starts
+}
+
+// validateLevels loads every level in turn and validates it.
+func validateLevels(w *engine.World) error {
+	if err := w.LoadPrefabs(prefabsPath); err != nil {
+		return err
+	}
+	var errs []error
+	for level := 0; ; level++ {
+		if _, err := fs.Stat(w.Assets, levelPath(level)); err != nil {
+			break
+		}
+		for _, entity := range w.GetGroup(levelEntitiesMatcher).GetEntities() {
+			w.DeleteEntity(entity)
+		}
+		if err := loadLevel(level, w); err != nil {
+			errs = append(errs, fmt.Errorf("level %d: %w", level, err))
+			continue
+		}
+		if err := validateLevel(w); err != nil {
+			errs = append(errs, fmt.Errorf("level %d: %w", level, err))
+		}
+	}
+	return errors.Join(errs...)
+}